@@ -5,12 +5,17 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/brevdev/brev-go-cli/internal/apply"
 	"github.com/brevdev/brev-go-cli/internal/auth"
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/dryrun"
 	"github.com/brevdev/brev-go-cli/internal/endpoint"
 	"github.com/brevdev/brev-go-cli/internal/env"
 	"github.com/brevdev/brev-go-cli/internal/initialize"
+	"github.com/brevdev/brev-go-cli/internal/logs"
 	"github.com/brevdev/brev-go-cli/internal/package_project"
+	"github.com/brevdev/brev-go-cli/internal/serve"
 	"github.com/brevdev/brev-go-cli/internal/status"
 	"github.com/brevdev/brev-go-cli/internal/sync"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
@@ -20,8 +25,14 @@ import (
 func main() {
 	t := terminal.New()
 
-	cmd := newCmdBrev(t)
-	if err := cmd.Execute(); err != nil {
+	plan := dryrun.NewPlan()
+	cmd := newCmdBrev(t, plan)
+	err := cmd.Execute()
+	if plan.Started() {
+		// list what was planned up to a failure as well
+		plan.Print(t)
+	}
+	if err != nil {
 		if _, ok := err.(*brev_errors.SuppressedError); ok {
 			// error suppressed
 		} else {
@@ -31,14 +42,18 @@ func main() {
 	}
 }
 
-func newCmdBrev(t *terminal.Terminal) *cobra.Command {
+func newCmdBrev(t *terminal.Terminal, plan *dryrun.Plan) *cobra.Command {
 	var verbose bool
 	var printVersion bool
+	var dryRun bool
 
 	brevCommand := &cobra.Command{
 		Use: "brev",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			t.SetVerbose(verbose)
+			if dryRun {
+				plan.Start()
+			}
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if printVersion {
@@ -73,6 +88,7 @@ func newCmdBrev(t *terminal.Terminal) *cobra.Command {
 
 	brevCommand.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	brevCommand.PersistentFlags().BoolVar(&printVersion, "version", false, "Print version output")
+	brevCommand.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the requests and file writes a command would make, without making them")
 	brevCommand.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
 		cmd.Println(err)
 		cmd.Println() // extra newline
@@ -80,21 +96,25 @@ func newCmdBrev(t *terminal.Terminal) *cobra.Command {
 		return &brev_errors.SuppressedError{}
 	})
 
-	createCmdTree(brevCommand, t)
+	createCmdTree(brevCommand, t, plan.Factory(brev_api.NewClient))
 	return brevCommand
 }
 
-func createCmdTree(brevCommand *cobra.Command, t *terminal.Terminal) {
-	brevCommand.AddCommand(endpoint.NewCmdEndpoint(t))
+func createCmdTree(brevCommand *cobra.Command, t *terminal.Terminal, newClient brev_api.ClientFactory) {
+	brevCommand.AddCommand(endpoint.NewCmdEndpoint(t, newClient))
 	brevCommand.AddCommand(auth.NewCmdLogin(t))
-	brevCommand.AddCommand(package_project.NewCmdPackage(t))
-	brevCommand.AddCommand(initialize.NewCmdClone(t))
-	brevCommand.AddCommand(initialize.NewCmdInit(t))
-	brevCommand.AddCommand(env.NewCmdEnv(t))
-	brevCommand.AddCommand(status.NewCmdStatus(t))
-	brevCommand.AddCommand(sync.NewCmdPull(t))
-	brevCommand.AddCommand(sync.NewCmdPush(t))
-	brevCommand.AddCommand(sync.NewCmdDiff(t))
+	brevCommand.AddCommand(package_project.NewCmdPackage(t, newClient))
+	brevCommand.AddCommand(initialize.NewCmdClone(t, newClient))
+	brevCommand.AddCommand(initialize.NewCmdInit(t, newClient))
+	brevCommand.AddCommand(env.NewCmdEnv(t, newClient))
+	brevCommand.AddCommand(status.NewCmdStatus(t, newClient))
+	brevCommand.AddCommand(sync.NewCmdPull(t, newClient))
+	brevCommand.AddCommand(sync.NewCmdPush(t, newClient))
+	brevCommand.AddCommand(sync.NewCmdDiff(t, newClient))
+	brevCommand.AddCommand(sync.NewCmdSync(t, newClient))
+	brevCommand.AddCommand(apply.NewCmdApply(t, newClient))
+	brevCommand.AddCommand(logs.NewCmdLogs(t, newClient))
+	brevCommand.AddCommand(serve.NewCmdServe(t, newClient))
 	brevCommand.AddCommand(&completionCmd)
 }
 
@@ -0,0 +1,280 @@
+package apply
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func apply(t *terminal.Terminal, newClient brev_api.ClientFactory, specPath string, prune bool) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		return err
+	}
+	if specPath == "" {
+		specPath = SpecPath(paths.Root)
+	}
+
+	spec, err := LoadSpec(specPath)
+	if err != nil {
+		return err
+	}
+
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
+	}
+	if spec.Project != project.Name {
+		return fmt.Errorf("%s describes project %s, but this is project %s", specPath, spec.Project, project.Name)
+	}
+
+	endpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return err
+	}
+	packages, err := brevCtx.Remote.GetPackages(*project, nil)
+	if err != nil {
+		return err
+	}
+	variables, err := brevCtx.Remote.GetVariables(*project, nil)
+	if err != nil {
+		return err
+	}
+
+	p := planApply(spec, endpoints, packages, variables, prune)
+	if p.empty() {
+		t.Vprint(t.Green("Project %s already matches %s 🥞", project.Name, specPath))
+		return nil
+	}
+	t.Vprint("Applying " + specPath + ":")
+	p.print(t)
+
+	// read every value up front so a missing one does not leave the project half applied
+	values, err := variableValues(t, p.addVariables)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range p.addVariables {
+		_, err := brevCtx.Remote.SetVariable(*project, v, values[v])
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range p.removeVariables {
+		err := brevCtx.Remote.DeleteVariable(v.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range p.addPackages {
+		_, err := brevCtx.Remote.SetPackage(*project, v.Name, v.Version)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range p.updatePackages {
+		err := replacePackage(t, brevCtx, *project, v)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range p.removePackages {
+		err := brevCtx.Remote.DeletePackage(v.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = applyEndpoints(t, brevCtx, paths, project, p)
+	if err != nil {
+		return err
+	}
+
+	t.Vprint(t.Green("\nProject %s now matches %s 🥞", project.Name, specPath))
+	return nil
+}
+
+// replacePackage reinstalls a package at its declared version. Packages are installed
+// at a single version, so the installed one is removed first. Should the declared
+// version fail to install, the removed version is installed again, and the package is
+// reported as left uninstalled if that fails too.
+func replacePackage(t *terminal.Terminal, brevCtx *brev_ctx.BrevContext, project brev_api.Project, update packageUpdate) error {
+	installed := packageLabel(update.installed.Name, update.installed.Version)
+	declared := packageLabel(update.spec.Name, update.spec.Version)
+
+	err := brevCtx.Remote.DeletePackage(update.installed.Id)
+	if err != nil {
+		return err
+	}
+	_, err = brevCtx.Remote.SetPackage(project, update.spec.Name, update.spec.Version)
+	if err == nil {
+		return nil
+	}
+
+	_, restoreErr := brevCtx.Remote.SetPackage(project, update.installed.Name, update.installed.Version)
+	if restoreErr != nil {
+		t.Errprint(restoreErr, fmt.Sprintf("Failed to install %s again", installed))
+		return fmt.Errorf("failed to install %s, and package %s was left uninstalled: %w", declared, update.spec.Name, err)
+	}
+	return fmt.Errorf("failed to install %s, %s was installed again: %w", declared, installed, err)
+}
+
+// applyEndpoints converges the remote endpoints and keeps endpoints.json, the
+// endpoint files and the sync manifest in step with them
+func applyEndpoints(t *terminal.Terminal, brevCtx *brev_ctx.BrevContext, paths *layout.Paths, project *brev_api.Project, p plan) error {
+	localEndpoints, err := brevCtx.Local.GetEndpoints(nil)
+	if err != nil {
+		return err
+	}
+	manifest, err := brevCtx.Local.GetManifest()
+	if err != nil {
+		return err
+	}
+
+	// save local state even if a later endpoint fails, as the earlier ones did change
+	var applyErr error
+	for _, v := range p.addEndpoints {
+		fileName := paths.Layout.EndpointFile(v.Name)
+
+		// an existing file is deployed as the endpoint's code
+		var code string
+		exists, err := files.Exists(paths.Endpoint(v.Name))
+		if err != nil {
+			applyErr = err
+			break
+		}
+		if exists {
+			code, err = files.ReadString(paths.Endpoint(v.Name))
+			if err != nil {
+				applyErr = err
+				break
+			}
+		}
+
+		endpoint, err := brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
+			ProjectId: project.Id,
+			Name:      v.Name,
+			Methods:   v.Methods,
+			Code:      code,
+		})
+		if err != nil {
+			applyErr = err
+			break
+		}
+		localEndpoints = append(localEndpoints, *endpoint)
+
+		if !exists {
+			err = files.OverwriteString(paths.Endpoint(v.Name), endpoint.Code)
+			if err != nil {
+				applyErr = err
+				break
+			}
+		}
+		manifest.Record(fileName, endpoint.Code)
+	}
+
+	for _, v := range p.updateEndpoints {
+		if applyErr != nil {
+			break
+		}
+		// update using the remote code so local changes are not pushed along the way
+		updated, err := brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
+			Id:      v.endpoint.Id,
+			Name:    v.endpoint.Name,
+			Methods: v.methods,
+			Code:    v.endpoint.Code,
+		})
+		if err != nil {
+			applyErr = err
+			break
+		}
+		for i := range localEndpoints {
+			if localEndpoints[i].Id == updated.Id {
+				localEndpoints[i].Methods = updated.Methods
+			}
+		}
+	}
+
+	for _, v := range p.removeEndpoints {
+		if applyErr != nil {
+			break
+		}
+		err := brevCtx.Remote.DeleteEndpoint(v.Id)
+		if err != nil {
+			applyErr = err
+			break
+		}
+		var kept []brev_api.Endpoint
+		for _, local := range localEndpoints {
+			if local.Id != v.Id {
+				kept = append(kept, local)
+			}
+		}
+		localEndpoints = kept
+		delete(manifest, paths.Layout.EndpointFile(v.Name))
+
+		exists, _ := files.Exists(paths.Endpoint(v.Name))
+		if exists {
+			t.Vprint(t.Yellow("Kept %s, delete it yourself if it is no longer needed", paths.Layout.EndpointFile(v.Name)))
+		}
+	}
+
+	err = brevCtx.Local.SetEndpoints(localEndpoints)
+	if err != nil {
+		return err
+	}
+	err = brevCtx.Local.SetManifest(manifest)
+	if err != nil {
+		return err
+	}
+	return applyErr
+}
+
+// variableValues returns the values of the given variables, taken from the
+// environment or asked for when running in a terminal
+func variableValues(t *terminal.Terminal, names []string) (map[string]string, error) {
+	values := make(map[string]string)
+	var missing []string
+	for _, name := range names {
+		value, ok := os.LookupEnv(name)
+		if ok {
+			values[name] = value
+			continue
+		}
+		if !term.IsTerminal(int(syscall.Stdin)) {
+			missing = append(missing, name)
+			continue
+		}
+
+		t.Vprintf("Enter value for %s: ", name)
+		bytepw, err := term.ReadPassword(int(syscall.Stdin))
+		t.Vprint("")
+		if err != nil {
+			return nil, err
+		}
+		values[name] = string(bytepw)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no value for %s: set them in the environment or run brev apply in a terminal", strings.Join(missing, ", "))
+	}
+	return values, nil
+}
@@ -0,0 +1,239 @@
+package apply
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_api/brevtest"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func TestLoadSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "brev-spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    *Spec
+		wantErr bool
+	}{
+		{
+			name: "full",
+			spec: "project: p\nendpoints:\n  - name: hello\n    methods: [post, get]\npackages:\n  - name: requests\n    version: 2.0\nvariables: [API_KEY]\n",
+			want: &Spec{
+				Project:   "p",
+				Endpoints: []EndpointSpec{{Name: "hello", Methods: []string{"GET", "POST"}}},
+				Packages:  []PackageSpec{{Name: "requests", Version: "2.0"}},
+				Variables: []string{"API_KEY"},
+			},
+		},
+		{name: "missing project", spec: "endpoints:\n  - name: hello\n", wantErr: true},
+		{name: "unknown field", spec: "project: p\nendpoint:\n  - name: hello\n", wantErr: true},
+		{name: "unknown method", spec: "project: p\nendpoints:\n  - name: hello\n    methods: [PATCH]\n", wantErr: true},
+		{name: "duplicate endpoint", spec: "project: p\nendpoints:\n  - name: a\n  - name: a\n", wantErr: true},
+		{name: "duplicate package", spec: "project: p\npackages:\n  - name: a\n  - name: a\n", wantErr: true},
+		{name: "invalid variable", spec: "project: p\nvariables: [API-KEY]\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "brev.yaml")
+			files.OverwriteString(path, tt.spec)
+			got, err := LoadSpec(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadSpec() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake, "hello", "stale")
+
+	fake.AddPackage(project.Id, "requests", "2.0")
+	fake.AddPackage(project.Id, "numpy", "")
+	fake.AddVariable(project.Id, "OLD_KEY", "x")
+	os.Setenv("API_KEY", "secret")
+	defer os.Unsetenv("API_KEY")
+
+	local := "def post():\n    return 1\n"
+	files.OverwriteString(filepath.Join(path, "local.py"), local)
+	files.OverwriteString(SpecPath(path), `project: project
+endpoints:
+  - name: hello
+    methods: [GET, PUT]
+  - name: local
+  - name: fresh
+    methods: [GET]
+packages:
+  - name: requests
+    version: "2.25.1"
+  - name: flask
+variables:
+  - API_KEY
+`)
+
+	if err := apply(terminal.New(), fake.Factory(), "", true); err != nil {
+		t.Fatalf("apply() returned error: %v", err)
+	}
+
+	remote := make(map[string]brev_api.Endpoint)
+	for _, v := range fake.Endpoints {
+		remote[v.Name] = v
+	}
+	if len(remote) != 3 {
+		t.Errorf("remote endpoints = %v, want hello, local and fresh", fake.Endpoints)
+	}
+	if !reflect.DeepEqual(remote["hello"].Methods, []string{"GET", "PUT"}) {
+		t.Errorf("hello methods = %v, want [GET PUT]", remote["hello"].Methods)
+	}
+	if remote["local"].Code != local || !reflect.DeepEqual(remote["local"].Methods, []string{"POST"}) {
+		t.Errorf("local endpoint = %+v, want the local file's code and inferred methods", remote["local"])
+	}
+	if _, err := os.Stat(filepath.Join(path, "fresh.py")); err != nil {
+		t.Errorf("fresh.py was not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "stale.py")); err != nil {
+		t.Errorf("stale.py should be kept: %v", err)
+	}
+
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+	localEndpoints, _ := brevCtx.Local.GetEndpoints(nil)
+	if len(localEndpoints) != 3 {
+		t.Errorf("endpoints.json holds %d endpoints, want 3", len(localEndpoints))
+	}
+	manifest, _ := brevCtx.Local.GetManifest()
+	if !manifest.Unchanged("local.py", local) {
+		t.Errorf("manifest does not record local.py")
+	}
+
+	packages := make(map[string]string)
+	for _, v := range fake.Packages {
+		packages[v.Name] = v.Version
+	}
+	if !reflect.DeepEqual(packages, map[string]string{"requests": "2.25.1", "flask": ""}) {
+		t.Errorf("packages = %v, want requests 2.25.1 and flask", packages)
+	}
+	if len(fake.Variables) != 1 || fake.Variables[0].Name != "API_KEY" {
+		t.Errorf("variables = %v, want API_KEY", fake.Variables)
+	}
+
+	// a second run has nothing left to do
+	endpoints := len(fake.Endpoints)
+	ids := fake.Packages[0].Id
+	if err := apply(terminal.New(), fake.Factory(), "", true); err != nil {
+		t.Fatalf("second apply() returned error: %v", err)
+	}
+	if len(fake.Endpoints) != endpoints || fake.Packages[0].Id != ids {
+		t.Errorf("second apply() changed the remote project")
+	}
+}
+
+func TestApplyWithoutPrune(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake, "hello")
+
+	fake.AddPackage(project.Id, "numpy", "")
+	files.OverwriteString(SpecPath(path), "project: project\npackages:\n  - name: requests\n")
+
+	if err := apply(terminal.New(), fake.Factory(), "", false); err != nil {
+		t.Fatalf("apply() returned error: %v", err)
+	}
+	if len(fake.Endpoints) != 1 || len(fake.Packages) != 2 {
+		t.Errorf("apply() without --prune removed undeclared resources")
+	}
+}
+
+func TestApplyPackageNames(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake)
+
+	fake.AddPackage(project.Id, "pyyaml", "5.4.1")
+	files.OverwriteString(SpecPath(path), "project: project\npackages:\n  - name: PyYAML\n")
+
+	// PyYAML is the installed pyyaml, so nothing is added or pruned
+	if err := apply(terminal.New(), fake.Factory(), "", true); err != nil {
+		t.Fatalf("apply() returned error: %v", err)
+	}
+	if len(fake.Packages) != 1 || fake.Packages[0].Name != "pyyaml" || fake.Packages[0].Version != "5.4.1" {
+		t.Errorf("packages = %+v, want only the installed pyyaml", fake.Packages)
+	}
+}
+
+func TestApplyMissingVariable(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake)
+
+	os.Unsetenv("BREV_TEST_MISSING")
+	files.OverwriteString(SpecPath(path), "project: project\npackages:\n  - name: requests\nvariables: [BREV_TEST_MISSING]\n")
+
+	if err := apply(terminal.New(), fake.Factory(), "", false); err == nil {
+		t.Fatalf("apply() returned no error for a variable without a value")
+	}
+	if len(fake.Packages) != 0 {
+		t.Errorf("apply() changed the project before failing")
+	}
+}
+
+func TestApplyWrongProject(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake)
+
+	files.OverwriteString(SpecPath(path), "project: other\n")
+	if err := apply(terminal.New(), fake.Factory(), "", false); err == nil {
+		t.Fatalf("apply() returned no error for a spec of another project")
+	}
+}
+
+// failingPackages fails to install packages at the given versions
+type failingPackages struct {
+	*brev_api.FakeClient
+	versions map[string]bool
+}
+
+func (c failingPackages) AddPackage(projectID string, name string, version string) (*brev_api.ResponseAddPackage, error) {
+	if c.versions[version] {
+		return nil, errors.New("no matching distribution")
+	}
+	return c.FakeClient.AddPackage(projectID, name, version)
+}
+
+func TestApplyFailedPackageUpdate(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake)
+	fake.AddPackage(project.Id, "numpy", "1.20.0")
+	files.OverwriteString(SpecPath(path), "project: project\npackages:\n  - name: numpy\n    version: 9.9.9\n")
+
+	// the removed version is installed again
+	client := failingPackages{FakeClient: fake, versions: map[string]bool{"9.9.9": true}}
+	newClient := func() (brev_api.Client, error) { return client, nil }
+	err := apply(terminal.New(), newClient, "", false)
+	if err == nil || !strings.Contains(err.Error(), "numpy==1.20.0 was installed again") {
+		t.Errorf("apply() error = %v, want the old version installed again", err)
+	}
+	if len(fake.Packages) != 1 || fake.Packages[0].Version != "1.20.0" {
+		t.Errorf("packages after a failed update = %+v, want numpy 1.20.0", fake.Packages)
+	}
+
+	// when that fails too, the package is reported as uninstalled
+	client.versions["1.20.0"] = true
+	err = apply(terminal.New(), newClient, "", false)
+	if err == nil || !strings.Contains(err.Error(), "package numpy was left uninstalled") {
+		t.Errorf("apply() error = %v, want numpy reported as left uninstalled", err)
+	}
+}
@@ -0,0 +1,46 @@
+package apply
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/cmdcontext"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func NewCmdApply(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var specPath string
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:         "apply",
+		Annotations: map[string]string{"project": ""},
+		Short:       "Make the remote project match .brev/brev.yaml",
+		Long: `Make the remote project match the endpoints, packages and variables declared
+in .brev/brev.yaml. Missing endpoints, packages and variables are created and
+endpoint methods and package versions are updated. Values of new variables are
+read from the environment, or asked for when running in a terminal.
+
+Anything not declared is left alone, unless --prune is given.`,
+		Example: `  brev apply
+  brev apply --prune
+  brev apply --file staging.yaml`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return apply(t, newClient, specPath, prune)
+		},
+	}
+
+	cmd.Flags().StringVarP(&specPath, "file", "f", "", "read the spec from this file instead of .brev/brev.yaml")
+	cmd.Flags().BoolVar(&prune, "prune", false, "remove endpoints, packages and variables the spec does not declare")
+
+	return cmd
+}
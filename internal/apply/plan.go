@@ -0,0 +1,164 @@
+package apply
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/package_project"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// methodsUpdate changes the methods an existing endpoint serves
+type methodsUpdate struct {
+	endpoint brev_api.Endpoint
+	methods  []string
+}
+
+// packageUpdate reinstalls a package at the declared version
+type packageUpdate struct {
+	installed brev_api.ProjectPackage
+	spec      PackageSpec
+}
+
+// plan lists the remote changes bringing a project in line with its spec.
+// Resources missing from the spec are only removed when pruning.
+type plan struct {
+	addVariables    []string
+	removeVariables []brev_api.ProjectVariable
+
+	addPackages    []PackageSpec
+	updatePackages []packageUpdate
+	removePackages []brev_api.ProjectPackage
+
+	addEndpoints    []EndpointSpec
+	updateEndpoints []methodsUpdate
+	removeEndpoints []brev_api.Endpoint
+}
+
+func planApply(spec *Spec, endpoints []brev_api.Endpoint, packages []brev_api.ProjectPackage, variables []brev_api.ProjectVariable, prune bool) plan {
+	var p plan
+
+	declaredVariables := make(map[string]bool)
+	for _, v := range spec.Variables {
+		declaredVariables[v] = true
+	}
+	existingVariables := make(map[string]bool)
+	for _, v := range variables {
+		existingVariables[v.Name] = true
+		if prune && !declaredVariables[v.Name] {
+			p.removeVariables = append(p.removeVariables, v)
+		}
+	}
+	for _, v := range spec.Variables {
+		if !existingVariables[v] {
+			p.addVariables = append(p.addVariables, v)
+		}
+	}
+
+	// packages are matched the way pip compares names, so PyYAML declares pyyaml
+	declaredPackages := make(map[string]PackageSpec)
+	for _, v := range spec.Packages {
+		declaredPackages[package_project.NormalizeName(v.Name)] = v
+	}
+	installedPackages := make(map[string]brev_api.ProjectPackage)
+	for _, v := range packages {
+		installedPackages[package_project.NormalizeName(v.Name)] = v
+		declared, ok := declaredPackages[package_project.NormalizeName(v.Name)]
+		if !ok {
+			if prune {
+				p.removePackages = append(p.removePackages, v)
+			}
+			continue
+		}
+		if declared.Version != "" && declared.Version != v.Version {
+			p.updatePackages = append(p.updatePackages, packageUpdate{installed: v, spec: declared})
+		}
+	}
+	for _, v := range spec.Packages {
+		if _, ok := installedPackages[package_project.NormalizeName(v.Name)]; !ok {
+			p.addPackages = append(p.addPackages, v)
+		}
+	}
+
+	declaredEndpoints := make(map[string]EndpointSpec)
+	for _, v := range spec.Endpoints {
+		declaredEndpoints[v.Name] = v
+	}
+	existingEndpoints := make(map[string]bool)
+	for _, v := range endpoints {
+		existingEndpoints[v.Name] = true
+		declared, ok := declaredEndpoints[v.Name]
+		if !ok {
+			if prune {
+				p.removeEndpoints = append(p.removeEndpoints, v)
+			}
+			continue
+		}
+		if len(declared.Methods) > 0 && !brev_api.SameMethods(declared.Methods, v.Methods) {
+			p.updateEndpoints = append(p.updateEndpoints, methodsUpdate{endpoint: v, methods: declared.Methods})
+		}
+	}
+	for _, v := range spec.Endpoints {
+		if !existingEndpoints[v.Name] {
+			p.addEndpoints = append(p.addEndpoints, v)
+		}
+	}
+
+	sort.Strings(p.addVariables)
+	sort.Slice(p.removeVariables, func(i, j int) bool { return p.removeVariables[i].Name < p.removeVariables[j].Name })
+	sort.Slice(p.addPackages, func(i, j int) bool { return p.addPackages[i].Name < p.addPackages[j].Name })
+	sort.Slice(p.updatePackages, func(i, j int) bool { return p.updatePackages[i].spec.Name < p.updatePackages[j].spec.Name })
+	sort.Slice(p.removePackages, func(i, j int) bool { return p.removePackages[i].Name < p.removePackages[j].Name })
+	sort.Slice(p.addEndpoints, func(i, j int) bool { return p.addEndpoints[i].Name < p.addEndpoints[j].Name })
+	sort.Slice(p.updateEndpoints, func(i, j int) bool { return p.updateEndpoints[i].endpoint.Name < p.updateEndpoints[j].endpoint.Name })
+	sort.Slice(p.removeEndpoints, func(i, j int) bool { return p.removeEndpoints[i].Name < p.removeEndpoints[j].Name })
+	return p
+}
+
+func (p plan) empty() bool {
+	return len(p.addVariables) == 0 && len(p.removeVariables) == 0 &&
+		len(p.addPackages) == 0 && len(p.updatePackages) == 0 && len(p.removePackages) == 0 &&
+		len(p.addEndpoints) == 0 && len(p.updateEndpoints) == 0 && len(p.removeEndpoints) == 0
+}
+
+func (p plan) print(t *terminal.Terminal) {
+	for _, v := range p.addVariables {
+		t.Vprint(t.Green("  + variable  %s", v))
+	}
+	for _, v := range p.removeVariables {
+		t.Vprint(t.Red("  - variable  %s", v.Name))
+	}
+	for _, v := range p.addPackages {
+		t.Vprint(t.Green("  + package   %s", packageLabel(v.Name, v.Version)))
+	}
+	for _, v := range p.updatePackages {
+		t.Vprint(t.Yellow("  ~ package   %s -> %s", packageLabel(v.installed.Name, v.installed.Version), v.spec.Version))
+	}
+	for _, v := range p.removePackages {
+		t.Vprint(t.Red("  - package   %s", packageLabel(v.Name, v.Version)))
+	}
+	for _, v := range p.addEndpoints {
+		t.Vprint(t.Green("  + endpoint  %s %s", v.Name, methodsLabel(v.Methods)))
+	}
+	for _, v := range p.updateEndpoints {
+		t.Vprint(t.Yellow("  ~ endpoint  %s %s -> %s", v.endpoint.Name, methodsLabel(v.endpoint.Methods), methodsLabel(v.methods)))
+	}
+	for _, v := range p.removeEndpoints {
+		t.Vprint(t.Red("  - endpoint  %s", v.Name))
+	}
+}
+
+func packageLabel(name string, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "==" + version
+}
+
+func methodsLabel(methods []string) string {
+	if len(methods) == 0 {
+		return "[inferred]"
+	}
+	return "[" + strings.Join(methods, ", ") + "]"
+}
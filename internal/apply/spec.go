@@ -0,0 +1,128 @@
+package apply
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/files"
+)
+
+const specFile = "brev.yaml"
+
+var variablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Spec is the desired state of a project, as declared in .brev/brev.yaml.
+//
+// Example .brev/brev.yaml:
+//   project: my-project
+//   endpoints:
+//     - name: hello
+//       methods: [GET, POST]
+//   packages:
+//     - name: requests
+//       version: 2.25.1
+//   variables:
+//     - API_KEY
+type Spec struct {
+	Project   string         `yaml:"project"`
+	Endpoints []EndpointSpec `yaml:"endpoints"`
+	Packages  []PackageSpec  `yaml:"packages"`
+	Variables []string       `yaml:"variables"`
+}
+
+// EndpointSpec declares an endpoint. Without methods, a new endpoint serves those
+// its code handles and an existing endpoint keeps its methods.
+type EndpointSpec struct {
+	Name    string   `yaml:"name"`
+	Methods []string `yaml:"methods,omitempty"`
+}
+
+// PackageSpec declares a package. Without a version, any installed version will do.
+type PackageSpec struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version,omitempty"`
+}
+
+// SpecPath returns the path of the spec of the project at the given root
+func SpecPath(projectRoot string) string {
+	return filepath.Join(projectRoot, files.GetBrevDirectory(), specFile)
+}
+
+// LoadSpec reads and validates the spec at the given path. Unknown fields are
+// rejected so that typos do not go unnoticed.
+func LoadSpec(path string) (*Spec, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var spec Spec
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.SetStrict(true)
+	err = decoder.Decode(&spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	err = spec.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec in %s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+// Validate checks the spec for missing names, duplicates and unknown methods. It
+// normalizes the declared methods.
+func (s *Spec) Validate() error {
+	if s.Project == "" {
+		return fmt.Errorf("the project name is missing")
+	}
+
+	endpoints := make(map[string]bool)
+	for i, v := range s.Endpoints {
+		if v.Name == "" {
+			return fmt.Errorf("endpoint %d has no name", i+1)
+		}
+		if endpoints[v.Name] {
+			return fmt.Errorf("endpoint %s is declared twice", v.Name)
+		}
+		endpoints[v.Name] = true
+
+		methods, err := brev_api.ParseMethods(v.Methods)
+		if err != nil {
+			return fmt.Errorf("endpoint %s: %w", v.Name, err)
+		}
+		if len(methods) > 0 {
+			s.Endpoints[i].Methods = methods
+		}
+	}
+
+	packages := make(map[string]bool)
+	for i, v := range s.Packages {
+		if v.Name == "" {
+			return fmt.Errorf("package %d has no name", i+1)
+		}
+		if packages[v.Name] {
+			return fmt.Errorf("package %s is declared twice", v.Name)
+		}
+		packages[v.Name] = true
+	}
+
+	variables := make(map[string]bool)
+	for _, v := range s.Variables {
+		if !variablePattern.MatchString(v) {
+			return fmt.Errorf("%q is not a valid variable name", v)
+		}
+		if variables[v] {
+			return fmt.Errorf("variable %s is declared twice", v)
+		}
+		variables[v] = true
+	}
+	return nil
+}
@@ -1,18 +1,36 @@
 package auth
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
 func NewCmdLogin(t *terminal.Terminal) *cobra.Command {
+	var options loginOptions
+
 	cmd := &cobra.Command{
 		Use:         "login",
 		Annotations: map[string]string{"housekeeping": ""},
+		Short:       "Log in to Brev",
+		Long: `Log in to Brev in your browser.
+
+On a machine without a browser, like a remote dev box, use --no-browser to log in
+from a browser anywhere else. Where nobody can log in, like CI, use --token with a
+long-lived token, or set BREV_TOKEN for commands to use it without logging in.`,
+		Example: `  brev login
+  brev login --no-browser
+  echo "$TOKEN" | brev login --token`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return loginAndInitialize(t)
+			if options.noBrowser && options.token {
+				return errors.New("--no-browser and --token can't be used together")
+			}
+			return loginAndInitialize(t, options)
 		},
 	}
+	cmd.Flags().BoolVar(&options.noBrowser, "no-browser", false, "print the login URL and paste back the URL your browser is redirected to, or its code")
+	cmd.Flags().BoolVar(&options.token, "token", false, "log in with a long-lived token read from "+tokenEnvVar+" or stdin")
 	return cmd
 }
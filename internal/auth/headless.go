@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// tokenEnvVar holds a long-lived token for machines where nobody can log in, like CI
+const tokenEnvVar = "BREV_TOKEN"
+
+type loginOptions struct {
+	// noBrowser prints the auth URL and reads the redirect URL from stdin
+	noBrowser bool
+	// token reads a long-lived token from BREV_TOKEN or stdin
+	token bool
+}
+
+func getTokenFromEnv() *CotterOauthToken {
+	token := strings.TrimSpace(os.Getenv(tokenEnvVar))
+	if token == "" {
+		return nil
+	}
+	return &CotterOauthToken{AccessToken: token}
+}
+
+// pasteCotterToken asks the user to open the auth URL on any machine and paste the
+// URL their browser is redirected to once they are logged in. The redirect fails to
+// load since nothing listens on this machine's port, but its query holds the code.
+func pasteCotterToken(t *terminal.Terminal, cotterURL string, codeVerifier string, state string, in io.Reader) (*CotterOauthToken, error) {
+	t.Vprint(t.Yellow("Open this URL in a browser on any machine and log in:\n"))
+	t.Vprint(cotterURL)
+	t.Vprint(t.Yellow("\nYour browser is then sent to a page on %s which fails to load.", localEndpoint))
+
+	code, challengeID, err := readRedirect(t, in, state)
+	if err != nil {
+		return nil, err
+	}
+	return requestCotterToken(code, challengeID, codeVerifier, localEndpoint)
+}
+
+// readRedirect reads the pasted redirect URL, or only the code in it, in which case
+// the challenge ID of the same URL is asked for as well
+func readRedirect(t *terminal.Terminal, in io.Reader, state string) (code string, challengeID string, err error) {
+	reader := bufio.NewReader(in)
+	t.Vprintf("Paste the URL of that page, or the code in it, here: ")
+	line, err := readLine(reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the redirect URL: %w", err)
+	}
+
+	code, challengeID, err = parseRedirect(line, state)
+	if err != nil || challengeID != "" {
+		return code, challengeID, err
+	}
+
+	t.Vprintf("Paste the challenge_id of the same URL here: ")
+	line, err = readLine(reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the challenge_id: %w", err)
+	}
+	challengeID = strings.TrimSpace(line)
+	if challengeID == "" {
+		return "", "", errors.New("no challenge_id given, paste the whole URL from the browser's address bar instead")
+	}
+	return code, challengeID, nil
+}
+
+// readLine reads a line, which may be the last one without a newline
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return line, nil
+}
+
+// parseRedirect returns the code and challenge ID of the redirect after logging in.
+// It accepts the whole redirect URL, only its query, or only the code, which leaves
+// the challenge ID empty.
+func parseRedirect(redirect string, state string) (code string, challengeID string, err error) {
+	redirect = strings.TrimSpace(redirect)
+	if redirect != "" && !strings.ContainsAny(redirect, "?=&/") {
+		return redirect, "", nil
+	}
+	if i := strings.Index(redirect, "?"); i >= 0 {
+		redirect = redirect[i+1:]
+	}
+	q, err := url.ParseQuery(redirect)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse the redirect URL: %w", err)
+	}
+
+	if q.Get("error") == "" && (q.Get("code") == "" || q.Get("challenge_id") == "") {
+		return "", "", errors.New("the redirect URL has no code and challenge_id, paste the whole URL from the browser's address bar")
+	}
+	return parseCallback(q, state)
+}
+
+// loginWithToken saves a long-lived token taken from BREV_TOKEN, or else from stdin
+func loginWithToken(t *terminal.Terminal, in io.Reader) error {
+	token := getTokenFromEnv()
+	if token == nil {
+		value, err := readToken(t, in)
+		if err != nil {
+			t.Errprint(err, "Failed to read auth token")
+			return err
+		}
+		token = &CotterOauthToken{AccessToken: value}
+	}
+
+	err := writeTokenToBrevConfigFile(token)
+	if err != nil {
+		t.Errprint(err, "Failed to write auth token to file")
+		return err
+	}
+
+	printLoggedIn(t)
+	return nil
+}
+
+// readToken prompts for the token without echoing it on a terminal, otherwise the
+// token is all of stdin so it can be piped in
+func readToken(t *terminal.Terminal, in io.Reader) (string, error) {
+	var value []byte
+	var err error
+	if f, ok := in.(*os.File); ok && f == os.Stdin && term.IsTerminal(int(syscall.Stdin)) {
+		t.Vprintf("Paste your token: ")
+		value, err = term.ReadPassword(int(syscall.Stdin))
+		t.Vprint("")
+	} else {
+		value, err = ioutil.ReadAll(in)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(value))
+	if token == "" {
+		return "", fmt.Errorf("no token given: pipe it to brev login --token or set %s", tokenEnvVar)
+	}
+	return token, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func TestParseRedirect(t *testing.T) {
+	tests := []struct {
+		in              string
+		wantCode        string
+		wantChallengeID string
+		wantErr         bool
+	}{
+		{in: "http://localhost:8395/?code=abc&challenge_id=42&state=xyz\n", wantCode: "abc", wantChallengeID: "42"},
+		{in: "code=abc&challenge_id=42&state=xyz", wantCode: "abc", wantChallengeID: "42"},
+		{in: "http://localhost:8395/?code=abc&challenge_id=42&state=other", wantErr: true},
+		{in: "http://localhost:8395/?error=access_denied&state=xyz", wantErr: true},
+		{in: "http://localhost:8395/?code=abc&state=xyz", wantErr: true},
+		{in: "abc\n", wantCode: "abc"},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		code, challengeID, err := parseRedirect(tt.in, "xyz")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRedirect(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if code != tt.wantCode || challengeID != tt.wantChallengeID {
+			t.Errorf("parseRedirect(%q) = %q, %q, want %q, %q", tt.in, code, challengeID, tt.wantCode, tt.wantChallengeID)
+		}
+	}
+}
+
+func TestReadRedirect(t *testing.T) {
+	code, challengeID, err := readRedirect(terminal.New(), strings.NewReader("http://localhost:8395/?code=abc&challenge_id=42&state=xyz\n"), "xyz")
+	if err != nil || code != "abc" || challengeID != "42" {
+		t.Errorf("readRedirect() = %q, %q, %v, want the code and challenge_id of the URL", code, challengeID, err)
+	}
+
+	// with only the code pasted, the challenge_id is asked for next
+	code, challengeID, err = readRedirect(terminal.New(), strings.NewReader("abc\n42"), "xyz")
+	if err != nil || code != "abc" || challengeID != "42" {
+		t.Errorf("readRedirect() = %q, %q, %v, want the pasted code and challenge_id", code, challengeID, err)
+	}
+
+	if _, _, err := readRedirect(terminal.New(), strings.NewReader("abc\n"), "xyz"); err == nil {
+		t.Errorf("readRedirect() accepted a code without a challenge_id")
+	}
+}
+
+func TestLoginWithToken(t *testing.T) {
+	home, err := ioutil.TempDir("", "brev-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHome := os.Getenv("HOME")
+	oldToken := os.Getenv(tokenEnvVar)
+	os.Setenv("HOME", home)
+	os.Unsetenv(tokenEnvVar)
+	defer func() {
+		os.Setenv("HOME", oldHome)
+		os.Setenv(tokenEnvVar, oldToken)
+		os.RemoveAll(home)
+	}()
+
+	if err := loginWithToken(terminal.New(), strings.NewReader("  \n")); err == nil {
+		t.Errorf("loginWithToken() accepted an empty token")
+	}
+
+	if err := loginWithToken(terminal.New(), strings.NewReader("long-lived\n")); err != nil {
+		t.Fatalf("loginWithToken() returned error: %v", err)
+	}
+	// the saved token is used as it is, without a round trip to refresh it
+	token, err := GetToken()
+	if err != nil || token.AccessToken != "long-lived" {
+		t.Errorf("GetToken() = %+v, %v, want the saved token", token, err)
+	}
+
+	os.Setenv(tokenEnvVar, "from-env")
+	token, err = GetToken()
+	if err != nil || token.AccessToken != "from-env" {
+		t.Errorf("GetToken() = %+v, %v, want the token from %s", token, err, tokenEnvVar)
+	}
+}
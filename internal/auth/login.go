@@ -7,7 +7,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"runtime"
@@ -30,8 +32,12 @@ const (
 	cotterBackendEndpoint = "https://www.cotter.app/api/v0"
 	cotterTokenEndpoint   = "https://www.cotter.app/api/v0/token"
 	cotterJwksEndpoint    = "https://www.cotter.app/api/v0/token/jwks"
-	localPort             = "8395"
-	localEndpoint         = "http://localhost:" + localPort
+	// localEndpoint is where the browser is redirected to when logging in with
+	// --no-browser. Nothing listens there, the redirect URL is pasted instead.
+	localEndpoint = "http://localhost:8395"
+
+	// loginTimeout is how long the browser login is waited for
+	loginTimeout = 5 * time.Minute
 
 	brevCredentialsFile      = "credentials.json"
 	globalActiveProjectsFile = "active_projects.json"
@@ -61,51 +67,87 @@ var successHTML embed.FS
 
 // Login performs a full round trip to Cotter:
 //   1. Generate a code verifier
-//   2. Open the Brev+Cotter auth URL in the default browser
-//   3. Start a local web server awaiting a redirect to localhost
+//   2. Start a local web server on a free loopback port
+//   3. Open the Brev+Cotter auth URL in the default browser, redirecting to that server
 //   4. Capture the Cotter token upon redirect
 //   5. Write the Cotter token to a file in the hidden brev directory
-func login(t *terminal.Terminal) error {
+// Without a browser, the URL is only printed and the redirect URL is pasted back
+// instead of being captured by the local web server.
+func login(t *terminal.Terminal, noBrowser bool) error {
 	cotterCodeVerifier := generateCodeVerifier()
+	state := generateStateValue()
 
-	cotterURL, err := buildCotterAuthURL(cotterCodeVerifier)
-	if err != nil {
-		t.Errprint(err, "Failed to construct auth URL")
-		return err
-	}
+	var token *CotterOauthToken
+	if noBrowser {
+		cotterURL, err := buildCotterAuthURL(cotterCodeVerifier, state, localEndpoint)
+		if err != nil {
+			t.Errprint(err, "Failed to construct auth URL")
+			return err
+		}
+
+		token, err = pasteCotterToken(t, cotterURL, cotterCodeVerifier, state, os.Stdin)
+		if err != nil {
+			t.Errprint(err, "Failed to read auth token")
+			return err
+		}
+	} else {
+		// only this machine may call back, on whichever port is free
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			err = &brev_errors.LoginServerError{Err: err}
+			t.Errprint(err, "")
+			return err
+		}
+		redirectURL := fmt.Sprintf("http://127.0.0.1:%d", listener.Addr().(*net.TCPAddr).Port)
 
-	// TODO: pretty print URL?
-	t.Print(cotterURL)
+		cotterURL, err := buildCotterAuthURL(cotterCodeVerifier, state, redirectURL)
+		if err != nil {
+			listener.Close()
+			t.Errprint(err, "Failed to construct auth URL")
+			return err
+		}
 
-	err = openInDefaultBrowser(cotterURL)
-	if err != nil {
-		t.Errprint(err, "Failed to open default browser")
-		return err
-	}
+		// TODO: pretty print URL?
+		t.Print(cotterURL)
 
-	token, err := captureCotterToken(cotterCodeVerifier)
-	if err != nil {
-		t.Errprint(err, "Failed to capture auth token")
-		return err
+		err = openInDefaultBrowser(cotterURL)
+		if err != nil {
+			listener.Close()
+			t.Errprint(err, "Failed to open default browser, try 'brev login --no-browser'")
+			return err
+		}
+
+		token, err = captureCotterToken(listener, redirectURL, state, cotterCodeVerifier, loginTimeout)
+		if err != nil {
+			t.Errprint(err, "Failed to capture auth token")
+			return err
+		}
 	}
 
-	err = writeTokenToBrevConfigFile(token)
+	err := writeTokenToBrevConfigFile(token)
 	if err != nil {
 		t.Errprint(err, "Failed to write auth token to file")
 		return err
 	}
 
+	printLoggedIn(t)
+	return nil
+}
+
+func printLoggedIn(t *terminal.Terminal) {
 	t.Vprint(
 		t.Green("\nYou're authenticated!\n") +
 			t.Yellow("\tbrev init") + t.Green(" to make a new project or\n") +
 			t.Yellow("\tbrev clone") + t.Green(" to clone your existing project"))
-
-	return nil
 }
 
-func loginAndInitialize(t *terminal.Terminal) error {
-
-	err := login(t)
+func loginAndInitialize(t *terminal.Terminal, options loginOptions) error {
+	var err error
+	if options.token {
+		err = loginWithToken(t, os.Stdin)
+	} else {
+		err = login(t, options.noBrowser)
+	}
 	if err != nil {
 		return err
 	}
@@ -147,13 +189,19 @@ func initializeActiveProjectsFile(t *terminal.Terminal) error {
 //   3. If valid, return
 //   4. If invalid, issue a refresh request to Cotter
 //   5. Write the refreshed Cotter token to a file in the hidden brev directory
+// A token in the BREV_TOKEN environment variable is used before the file.
 func GetToken() (*CotterOauthToken, error) {
+	if token := getTokenFromEnv(); token != nil {
+		return token, nil
+	}
+
 	token, err := getTokenFromBrevConfigFile()
 	if err != nil {
 		return nil, err
 	}
 
-	if token.isValid() {
+	// long-lived tokens saved by brev login --token have nothing to refresh them with
+	if token.RefreshToken == "" || token.isValid() {
 		return token, nil
 	}
 
@@ -216,8 +264,7 @@ func fetchCotterPublicKeySet() (*jose.JSONWebKeySet, error) {
 	return &cotterJWKS, nil
 }
 
-func buildCotterAuthURL(codeVerifier string) (string, error) {
-	state := generateStateValue()
+func buildCotterAuthURL(codeVerifier string, state string, redirectURL string) (string, error) {
 	codeChallenge := generateCodeChallenge(codeVerifier)
 
 	request := &requests.RESTRequest{
@@ -225,7 +272,7 @@ func buildCotterAuthURL(codeVerifier string) (string, error) {
 		Endpoint: cotterEndpoint,
 		QueryParams: []requests.QueryParam{
 			{"api_key", getCotterAPIKey()},
-			{"redirect_url", localEndpoint},
+			{"redirect_url", redirectURL},
 			{"state", state},
 			{"code_challenge", codeChallenge},
 			{"type", "EMAIL"},
@@ -257,42 +304,76 @@ func openInDefaultBrowser(url string) error {
 	return exec.Command(cmd, args...).Start()
 }
 
-func captureCotterToken(codeVerifier string) (*CotterOauthToken, error) {
-	m := http.NewServeMux()
-	s := http.Server{Addr: ":" + localPort, Handler: m}
+// captureCotterToken serves the login callback on the listener until the browser is
+// redirected to it, then exchanges the code it brings for a token. The first callback
+// decides the outcome, and it fails if its state is not the one of this login.
+func captureCotterToken(listener net.Listener, redirectURL string, state string, codeVerifier string, timeout time.Duration) (*CotterOauthToken, error) {
+	type result struct {
+		token *CotterOauthToken
+		err   error
+	}
+	results := make(chan result, 1)
 
-	var token *CotterOauthToken
+	m := http.NewServeMux()
 	m.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		q := r.URL.Query()
-
-		errorParm := q.Get("error")
-		if errorParm != "" {
-			fmt.Println(errorParm)
-			// panic!
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
 		}
 
-		code := q.Get("code")
-		challengeID := q.Get("challenge_id")
+		var token *CotterOauthToken
+		code, challengeID, err := parseCallback(r.URL.Query(), state)
+		if err == nil {
+			token, err = requestCotterToken(code, challengeID, codeVerifier, redirectURL)
+		}
 
-		cotterToken, err := requestCotterToken(code, challengeID, codeVerifier)
 		if err != nil {
-			// panic!
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "%s\n\nReturn to your terminal for details.\n", err)
+		} else {
+			content, _ := successHTML.ReadFile("success.html")
+			w.Write(content)
 		}
 
-		token = cotterToken
-
-		content, _ := successHTML.ReadFile("success.html")
-		w.Write(content)
-
-		err = s.Shutdown(context.Background())
-		if err != nil {
-			// panic!
+		select {
+		case results <- result{token, err}:
+		default:
 		}
 	})
 
-	// blocks
-	s.ListenAndServe()
-	return token, nil
+	s := http.Server{Handler: m}
+	go s.Serve(listener)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.Shutdown(ctx)
+	}()
+
+	select {
+	case r := <-results:
+		return r.token, r.err
+	case <-time.After(timeout):
+		return nil, &brev_errors.LoginTimeoutError{Timeout: timeout}
+	}
+}
+
+// parseCallback returns the code and challenge ID the auth server redirected with, or
+// the error it redirected with instead
+func parseCallback(q url.Values, state string) (code string, challengeID string, err error) {
+	if errorParam := q.Get("error"); errorParam != "" {
+		return "", "", &brev_errors.LoginCallbackError{Code: errorParam, Description: q.Get("error_description")}
+	}
+	if q.Get("state") != state {
+		return "", "", &brev_errors.LoginStateMismatchError{}
+	}
+
+	code = q.Get("code")
+	challengeID = q.Get("challenge_id")
+	if code == "" || challengeID == "" {
+		return "", "", errors.New("the login callback has no code and challenge_id")
+	}
+	return code, challengeID, nil
 }
 
 func writeTokenToBrevConfigFile(token *CotterOauthToken) error {
@@ -303,7 +384,8 @@ func writeTokenToBrevConfigFile(token *CotterOauthToken) error {
 
 	brevCredentialsFile := home + "/" + files.GetBrevDirectory() + "/" + brevCredentialsFile
 
-	err = files.OverwriteJSON(brevCredentialsFile, token)
+	// refreshing the token is no change of the command's, so it is saved during dry runs
+	err = files.OverwriteJSONUnrecorded(brevCredentialsFile, token)
 	if err != nil {
 		return err
 	}
@@ -335,7 +417,7 @@ func getTokenFromBrevConfigFile() (*CotterOauthToken, error) {
 	return &token, nil
 }
 
-func requestCotterToken(code string, challengeID string, codeVerifier string) (*CotterOauthToken, error) {
+func requestCotterToken(code string, challengeID string, codeVerifier string, redirectURL string) (*CotterOauthToken, error) {
 	challengeIDInt, err := strconv.Atoi(challengeID)
 	if err != nil {
 		return nil, err
@@ -355,7 +437,7 @@ func requestCotterToken(code string, challengeID string, codeVerifier string) (*
 			CodeVerifier:      codeVerifier,
 			AuthorizationCode: code,
 			ChallengeId:       challengeIDInt,
-			RedirectURL:       localEndpoint,
+			RedirectURL:       redirectURL,
 		},
 	}
 	response, err := request.SubmitStrict()
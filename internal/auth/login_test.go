@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+)
+
+// callback starts capturing a token on a free loopback port and sends the browser's
+// redirect to it with the given query
+func callback(t *testing.T, query string, timeout time.Duration) (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirectURL := "http://" + listener.Addr().String()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := captureCotterToken(listener, redirectURL, "xyz", "verifier", timeout)
+		errs <- err
+	}()
+
+	status := 0
+	if query != "" {
+		response, err := http.Get(redirectURL + "/?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		status = response.StatusCode
+	}
+	return status, <-errs
+}
+
+func TestCaptureCotterTokenStateMismatch(t *testing.T) {
+	status, err := callback(t, "code=abc&challenge_id=42&state=other", time.Minute)
+	var mismatch *brev_errors.LoginStateMismatchError
+	if !errors.As(err, &mismatch) || status != http.StatusBadRequest {
+		t.Errorf("captureCotterToken() = %v with status %d, want a state mismatch with status 400", err, status)
+	}
+}
+
+func TestCaptureCotterTokenCallbackError(t *testing.T) {
+	_, err := callback(t, "error=access_denied&error_description=denied&state=xyz", time.Minute)
+	var callbackErr *brev_errors.LoginCallbackError
+	if !errors.As(err, &callbackErr) || callbackErr.Code != "access_denied" {
+		t.Errorf("captureCotterToken() = %v, want the access_denied callback error", err)
+	}
+}
+
+func TestCaptureCotterTokenTimeout(t *testing.T) {
+	_, err := callback(t, "", 10*time.Millisecond)
+	var timeoutErr *brev_errors.LoginTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("captureCotterToken() = %v, want a timeout", err)
+	}
+}
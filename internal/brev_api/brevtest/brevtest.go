@@ -0,0 +1,66 @@
+// Package brevtest sets up projects on disk for tests running against a FakeClient
+package brevtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
+)
+
+// SetupProject seeds the fake with a project and the given endpoints and checks it
+// out into a temporary directory, with a file for each endpoint and the shared module
+// in the default layout. HOME is redirected to the temporary directory and the
+// project becomes the working directory until the test ends. It returns the project
+// and its local path.
+func SetupProject(t *testing.T, fake *brev_api.FakeClient, endpointNames ...string) (brev_api.Project, string) {
+	root, err := ioutil.TempDir("", "brev-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "project")
+
+	oldHome := os.Getenv("HOME")
+	oldCwd, _ := os.Getwd()
+	os.Setenv("HOME", root)
+	t.Cleanup(func() {
+		os.Chdir(oldCwd)
+		os.Setenv("HOME", oldHome)
+		os.RemoveAll(root)
+	})
+
+	response, err := fake.CreateProject("project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := response.Project
+	for _, name := range endpointNames {
+		fake.CreateEndpoint(name, project.Id, nil, "")
+	}
+	endpoints, _ := fake.GetEndpoints()
+
+	paths := layout.Paths{Root: path, Layout: layout.Default}
+	files.OverwriteJSON(filepath.Join(root, ".brev", "active_projects.json"), []string{path})
+	files.OverwriteJSON(filepath.Join(path, ".brev", "projects.json"), project)
+	files.OverwriteJSON(filepath.Join(path, ".brev", "endpoints.json"), endpoints)
+
+	// like brev clone, the files are recorded as the base of later pushes and pulls
+	manifest := brev_ctx.Manifest{}
+	files.OverwriteString(paths.Module(response.Module.Name), response.Module.Source)
+	manifest.Record(layout.Default.ModuleFile(response.Module.Name), response.Module.Source)
+	for _, endpoint := range endpoints {
+		files.OverwriteString(paths.Endpoint(endpoint.Name), endpoint.Code)
+		manifest.Record(layout.Default.EndpointFile(endpoint.Name), endpoint.Code)
+	}
+	files.OverwriteJSON(filepath.Join(path, ".brev", files.GetManifestFile()), manifest)
+	if err := os.Chdir(path); err != nil {
+		t.Fatal(err)
+	}
+
+	return project, path
+}
@@ -0,0 +1,46 @@
+package brev_api
+
+import (
+	"github.com/brevdev/brev-go-cli/internal/auth"
+)
+
+// Client is the set of Brev API operations used by the CLI. Agent is the
+// network-backed implementation and FakeClient is an in-memory stand-in for tests.
+type Client interface {
+	GetProjects() ([]Project, error)
+	CreateProject(name string) (*ResponseCreateProject, error)
+
+	GetEndpoints() ([]Endpoint, error)
+	CreateEndpoint(name string, projectId string, methods []string, code string) (*ResponseUpdateEndpoint, error)
+	UpdateEndpoint(endpointID string, updateRequest RequestUpdateEndpoint) (*ResponseUpdateEndpoint, error)
+	RemoveEndpoint(endpointID string) (*ResponseRemoveEndpoint, error)
+
+	GetModules() (*Modules, error)
+	UpdateModule(moduleID string, source string) (*ResponseUpdateModule, error)
+
+	GetVariables(projectID string) ([]ProjectVariable, error)
+	AddVariable(projectID string, name string, value string) (*ResponseAddVariable, error)
+	RemoveVariable(variableID string) (*ResponseRemoveVariable, error)
+
+	GetPackages(projectID string) ([]ProjectPackage, error)
+	AddPackage(projectID string, name string, version string) (*ResponseAddPackage, error)
+	RemovePackage(packageID string) (*ResponseRemovePackage, error)
+
+	GetLogs(projectID string, logType string) ([]ProjectLog, error)
+}
+
+var _ Client = (*Agent)(nil)
+
+// ClientFactory constructs the Client used by a command. Commands are given a
+// factory rather than a Client so that authentication only happens when a
+// command actually talks to the Brev API.
+type ClientFactory func() (Client, error)
+
+// NewClient returns an Agent authorized with the locally stored auth token
+func NewClient() (Client, error) {
+	token, err := auth.GetToken()
+	if err != nil {
+		return nil, err
+	}
+	return &Agent{Key: token}, nil
+}
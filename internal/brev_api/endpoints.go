@@ -1,6 +1,10 @@
 package brev_api
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/brevdev/brev-go-cli/internal/requests"
 )
 
@@ -15,6 +19,62 @@ type Endpoint struct {
 	Code       string   `json:"code"`
 }
 
+// HTTPMethods are the request methods an endpoint may serve, in display order
+var HTTPMethods = []string{"GET", "POST", "PUT", "DELETE"}
+
+var handlerPattern = regexp.MustCompile(`(?m)^(?:async\s+)?def\s+(get|post|put|delete)\s*\(`)
+
+// ParseMethods validates the given request methods, ignoring case, and returns them
+// without duplicates in the order of HTTPMethods
+func ParseMethods(methods []string) ([]string, error) {
+	given := make(map[string]bool)
+	for _, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		known := false
+		for _, v := range HTTPMethods {
+			known = known || v == method
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown method %q: expected one of %s", method, strings.Join(HTTPMethods, ", "))
+		}
+		given[method] = true
+	}
+
+	parsed := []string{}
+	for _, v := range HTTPMethods {
+		if given[v] {
+			parsed = append(parsed, v)
+		}
+	}
+	return parsed, nil
+}
+
+// InferMethods returns the methods the endpoint code serves, going by the handler
+// functions such as get() and async post() defined at its top level
+func InferMethods(code string) []string {
+	var handlers []string
+	for _, match := range handlerPattern.FindAllStringSubmatch(code, -1) {
+		handlers = append(handlers, match[1])
+	}
+	methods, _ := ParseMethods(handlers)
+	return methods
+}
+
+// SameMethods reports whether both lists hold the same methods, in any order
+func SameMethods(a []string, b []string) bool {
+	a, errA := ParseMethods(a)
+	b, errB := ParseMethods(b)
+	if errA != nil || errB != nil || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 type Endpoints struct {
 	Endpoints []Endpoint `json:"endpoints"`
 }
@@ -53,7 +113,7 @@ func (a *Agent) GetEndpoints() ([]Endpoint, error) {
 			{"Authorization", "Bearer " + a.Key.AccessToken},
 		},
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +129,6 @@ func (a *Agent) GetEndpoints() ([]Endpoint, error) {
 
 const dummyCode = `import variables
 import shared
-import variables
 from global_storage import storage_context
 
 def get():
@@ -80,7 +139,15 @@ def post():
 
 `
 
-func (a *Agent) CreateEndpoint(name string, projectId string) (*ResponseUpdateEndpoint, error) {
+// CreateEndpoint creates an endpoint with the given code, or starter code if it is
+// empty. Without methods, the endpoint serves those its code handles.
+func (a *Agent) CreateEndpoint(name string, projectId string, methods []string, code string) (*ResponseUpdateEndpoint, error) {
+	if code == "" {
+		code = dummyCode
+	}
+	if len(methods) == 0 {
+		methods = InferMethods(code)
+	}
 	request := &requests.RESTRequest{
 		Method:   "POST",
 		Endpoint: brevEndpoint("_endpoint"),
@@ -93,13 +160,13 @@ func (a *Agent) CreateEndpoint(name string, projectId string) (*ResponseUpdateEn
 		Payload: RequestCreateEndpoint{
 			Name:      name,
 			ProjectId: projectId,
-			Methods:   []string{},
-			Code:      dummyCode,
+			Methods:   methods,
+			Code:      code,
 			Uri:       "/" + name,
 		},
 	}
 
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(request)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +192,7 @@ func (a *Agent) UpdateEndpoint(endpointID string, updateRequest RequestUpdateEnd
 		},
 		Payload: updateRequest,
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +217,7 @@ func (a *Agent) RemoveEndpoint(endpointID string) (*ResponseRemoveEndpoint, erro
 			{"Authorization", "Bearer " + a.Key.AccessToken},
 		},
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,51 @@
+package brev_api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMethods(t *testing.T) {
+	methods, err := ParseMethods([]string{"delete", " get", "GET", "Post"})
+	if err != nil {
+		t.Fatalf("ParseMethods() returned error: %v", err)
+	}
+	if want := []string{"GET", "POST", "DELETE"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("ParseMethods() = %v, want %v", methods, want)
+	}
+
+	if _, err := ParseMethods([]string{"GET", "PATCH"}); err == nil {
+		t.Error("ParseMethods() accepted PATCH")
+	}
+}
+
+func TestInferMethods(t *testing.T) {
+	code := `import shared
+
+def helper():
+    pass
+
+def delete(): pass
+
+def get ():
+    return {}
+
+async def put(request):
+    return {}
+
+class Handler:
+    def post(self):
+        pass
+
+# def put():
+`
+	if methods, want := InferMethods(code), []string{"GET", "PUT", "DELETE"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("InferMethods() = %v, want %v", methods, want)
+	}
+	if methods := InferMethods(dummyCode); !reflect.DeepEqual(methods, []string{"GET", "POST"}) {
+		t.Errorf("InferMethods(dummyCode) = %v, want [GET POST]", methods)
+	}
+	if !SameMethods([]string{"post", "GET"}, []string{"GET", "POST"}) || SameMethods([]string{"GET"}, []string{"GET", "POST"}) {
+		t.Error("SameMethods() compares methods by more than their set")
+	}
+}
@@ -0,0 +1,69 @@
+package brev_api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/requests"
+)
+
+// apiErrorPayload is the error body returned by the Brev API. Older endpoints
+// report the message as "error", newer ones as "message" or "detail".
+type apiErrorPayload struct {
+	Code    string `json:"code"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Detail  string `json:"detail"`
+}
+
+// submitStrict performs the request, decoding any error status into a typed BrevError
+func submitStrict(request *requests.RESTRequest) (*requests.RESTResponse, error) {
+	response, err := request.SubmitStrict()
+	if err != nil {
+		return nil, decodeAPIError(err)
+	}
+	return response, nil
+}
+
+// decodeAPIError converts a requests.RESTResponseError into the matching BrevError.
+// Errors of any other kind are returned unchanged.
+func decodeAPIError(err error) error {
+	var responseErr *requests.RESTResponseError
+	if !errors.As(err, &responseErr) {
+		return err
+	}
+
+	apiErr := brev_errors.APIError{
+		StatusCode: responseErr.ResponseStatusCode,
+		RequestURI: responseErr.RequestURI,
+	}
+
+	var payload apiErrorPayload
+	if json.Unmarshal(responseErr.ResponsePayload, &payload) == nil {
+		apiErr.Code = payload.Code
+		for _, message := range []string{payload.Message, payload.Error, payload.Detail} {
+			if message != "" {
+				apiErr.Message = message
+				break
+			}
+		}
+	} else {
+		apiErr.Message = strings.TrimSpace(string(responseErr.ResponsePayload))
+		if len(apiErr.Message) > 200 {
+			apiErr.Message = apiErr.Message[:200] + "..."
+		}
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		return &brev_errors.UnauthorizedError{APIError: apiErr}
+	case http.StatusNotFound:
+		return &brev_errors.RemoteNotFoundError{APIError: apiErr}
+	case http.StatusConflict:
+		return &brev_errors.NameConflictError{APIError: apiErr}
+	}
+	return &apiErr
+}
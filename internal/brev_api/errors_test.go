@@ -0,0 +1,83 @@
+package brev_api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/requests"
+)
+
+func TestDecodeAPIError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		payload     string
+		wantMessage string
+		wantCode    string
+		check       func(error) bool
+	}{
+		{
+			name:        "unauthorized",
+			status:      401,
+			payload:     `{"error": "token expired"}`,
+			wantMessage: "token expired",
+			check:       func(err error) bool { _, ok := err.(*brev_errors.UnauthorizedError); return ok },
+		},
+		{
+			name:        "not found",
+			status:      404,
+			payload:     `{"message": "no such project", "code": "project_not_found"}`,
+			wantMessage: "no such project",
+			wantCode:    "project_not_found",
+			check:       func(err error) bool { _, ok := err.(*brev_errors.RemoteNotFoundError); return ok },
+		},
+		{
+			name:        "conflict",
+			status:      409,
+			payload:     `{"detail": "endpoint name taken"}`,
+			wantMessage: "endpoint name taken",
+			check:       func(err error) bool { _, ok := err.(*brev_errors.NameConflictError); return ok },
+		},
+		{
+			name:        "non-json body",
+			status:      400,
+			payload:     "bad package name\n",
+			wantMessage: "bad package name",
+			check:       func(err error) bool { _, ok := err.(*brev_errors.APIError); return ok },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeAPIError(fmt.Errorf("wrapped: %w", &requests.RESTResponseError{
+				RequestURI:         "https://app.brev.dev/_api/_endpoint",
+				ResponseStatusCode: tt.status,
+				ResponsePayload:    []byte(tt.payload),
+			}))
+			if !tt.check(err) {
+				t.Fatalf("decodeAPIError() returned %T, want a different type", err)
+			}
+
+			var apiErr *brev_errors.APIError
+			var brevErr brev_errors.BrevError
+			if !errors.As(err, &brevErr) {
+				t.Fatalf("decodeAPIError() = %v, want a BrevError", err)
+			}
+			switch e := err.(type) {
+			case *brev_errors.UnauthorizedError:
+				apiErr = &e.APIError
+			case *brev_errors.RemoteNotFoundError:
+				apiErr = &e.APIError
+			case *brev_errors.NameConflictError:
+				apiErr = &e.APIError
+			case *brev_errors.APIError:
+				apiErr = e
+			}
+			if apiErr.Message != tt.wantMessage || apiErr.Code != tt.wantCode || apiErr.StatusCode != tt.status {
+				t.Errorf("decoded %+v, want message %q code %q", apiErr, tt.wantMessage, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package brev_api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+)
+
+// FakeClient is an in-memory implementation of Client for tests. Its exported
+// fields may be seeded directly before use and inspected afterwards; they hold
+// the full remote state across all projects, like the real API.
+//
+// Example usage:
+//   fake := brev_api.NewFakeClient()
+//   project := fake.AddProject("my-project")
+//   fake.Endpoints = append(fake.Endpoints, brev_api.Endpoint{...})
+//
+//   brevCtx, _ := brev_ctx.New(fake.Factory())
+type FakeClient struct {
+	Projects  []Project
+	Endpoints []Endpoint
+	Modules   []Module
+	Variables []ProjectVariable
+	Packages  []ProjectPackage
+	Logs      []ProjectLog
+
+	mu     sync.Mutex
+	nextID int
+}
+
+var _ Client = (*FakeClient)(nil)
+
+// NewFakeClient returns an empty FakeClient
+func NewFakeClient() *FakeClient {
+	return &FakeClient{}
+}
+
+// Factory returns a ClientFactory which always yields this FakeClient
+func (f *FakeClient) Factory() ClientFactory {
+	return func() (Client, error) {
+		return f, nil
+	}
+}
+
+// AddProject seeds a project, along with its shared module, and returns it
+func (f *FakeClient) AddProject(name string) Project {
+	response, _ := f.CreateProject(name)
+	return response.Project
+}
+
+func (f *FakeClient) newID(prefix string) string {
+	f.nextID += 1
+	return fmt.Sprintf("%s-%d", prefix, f.nextID)
+}
+
+func notFound(kind string, id string) error {
+	return &brev_errors.RemoteNotFoundError{APIError: brev_errors.APIError{
+		StatusCode: 404,
+		Code:       kind + "_not_found",
+		Message:    fmt.Sprintf("no %s with id %s", kind, id),
+	}}
+}
+
+func (f *FakeClient) GetProjects() ([]Project, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Project{}, f.Projects...), nil
+}
+
+func (f *FakeClient) CreateProject(name string) (*ResponseCreateProject, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	project := Project{
+		Id:     f.newID("proj"),
+		Name:   name,
+		Domain: fmt.Sprintf("https://%s.brev.test", name),
+	}
+	module := Module{
+		Id:        f.newID("mod"),
+		Name:      "shared",
+		ProjectId: project.Id,
+	}
+	f.Projects = append(f.Projects, project)
+	f.Modules = append(f.Modules, module)
+
+	return &ResponseCreateProject{Project: project, Module: module}, nil
+}
+
+func (f *FakeClient) GetEndpoints() ([]Endpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Endpoint{}, f.Endpoints...), nil
+}
+
+func (f *FakeClient) CreateEndpoint(name string, projectId string, methods []string, code string) (*ResponseUpdateEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if code == "" {
+		code = dummyCode
+	}
+	if len(methods) == 0 {
+		methods = InferMethods(code)
+	}
+
+	for _, endpoint := range f.Endpoints {
+		if endpoint.ProjectId == projectId && endpoint.Name == name {
+			return nil, &brev_errors.NameConflictError{APIError: brev_errors.APIError{
+				StatusCode: 409,
+				Message:    fmt.Sprintf("endpoint %s already exists", name),
+			}}
+		}
+	}
+
+	endpoint := Endpoint{
+		Id:        f.newID("ep"),
+		Name:      name,
+		Methods:   methods,
+		Uri:       "/" + name,
+		ProjectId: projectId,
+		Code:      code,
+	}
+	f.Endpoints = append(f.Endpoints, endpoint)
+
+	return &ResponseUpdateEndpoint{Endpoint: endpoint}, nil
+}
+
+func (f *FakeClient) UpdateEndpoint(endpointID string, updateRequest RequestUpdateEndpoint) (*ResponseUpdateEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, endpoint := range f.Endpoints {
+		if endpoint.Id == endpointID {
+			// URIs derived from the name follow it on rename
+			if endpoint.Uri == "/"+endpoint.Name {
+				f.Endpoints[i].Uri = "/" + updateRequest.Name
+			}
+			f.Endpoints[i].Name = updateRequest.Name
+			f.Endpoints[i].Methods = updateRequest.Methods
+			f.Endpoints[i].Code = updateRequest.Code
+			return &ResponseUpdateEndpoint{Endpoint: f.Endpoints[i]}, nil
+		}
+	}
+	return nil, notFound("endpoint", endpointID)
+}
+
+func (f *FakeClient) RemoveEndpoint(endpointID string) (*ResponseRemoveEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, endpoint := range f.Endpoints {
+		if endpoint.Id == endpointID {
+			f.Endpoints = append(f.Endpoints[:i], f.Endpoints[i+1:]...)
+			return &ResponseRemoveEndpoint{ID: endpointID, Success: true}, nil
+		}
+	}
+	return nil, notFound("endpoint", endpointID)
+}
+
+func (f *FakeClient) GetModules() (*Modules, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return &Modules{Modules: append([]Module{}, f.Modules...)}, nil
+}
+
+func (f *FakeClient) UpdateModule(moduleID string, source string) (*ResponseUpdateModule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, module := range f.Modules {
+		if module.Id == moduleID {
+			f.Modules[i].Source = source
+			return &ResponseUpdateModule{Module: f.Modules[i]}, nil
+		}
+	}
+	return nil, notFound("module", moduleID)
+}
+
+func (f *FakeClient) GetVariables(projectID string) ([]ProjectVariable, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var variables []ProjectVariable
+	for _, variable := range f.Variables {
+		if variable.ProjectId == projectID {
+			variables = append(variables, variable)
+		}
+	}
+	return variables, nil
+}
+
+func (f *FakeClient) AddVariable(projectID string, name string, value string) (*ResponseAddVariable, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	variable := ProjectVariable{
+		Id:        f.newID("var"),
+		Name:      name,
+		ProjectId: projectID,
+	}
+	f.Variables = append(f.Variables, variable)
+
+	return &ResponseAddVariable{Variable: variable}, nil
+}
+
+func (f *FakeClient) RemoveVariable(variableID string) (*ResponseRemoveVariable, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, variable := range f.Variables {
+		if variable.Id == variableID {
+			f.Variables = append(f.Variables[:i], f.Variables[i+1:]...)
+			return &ResponseRemoveVariable{ID: variableID}, nil
+		}
+	}
+	return nil, notFound("variable", variableID)
+}
+
+func (f *FakeClient) GetPackages(projectID string) ([]ProjectPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var packages []ProjectPackage
+	for _, projectPackage := range f.Packages {
+		if projectPackage.ProjectId == projectID {
+			packages = append(packages, projectPackage)
+		}
+	}
+	return packages, nil
+}
+
+func (f *FakeClient) AddPackage(projectID string, name string, version string) (*ResponseAddPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	projectPackage := ProjectPackage{
+		Id:        f.newID("pkg"),
+		Name:      name,
+		ProjectId: projectID,
+		Status:    "pending",
+		Version:   version,
+	}
+	f.Packages = append(f.Packages, projectPackage)
+
+	return &ResponseAddPackage{Package: projectPackage}, nil
+}
+
+func (f *FakeClient) RemovePackage(packageID string) (*ResponseRemovePackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, projectPackage := range f.Packages {
+		if projectPackage.Id == packageID {
+			f.Packages = append(f.Packages[:i], f.Packages[i+1:]...)
+			return &ResponseRemovePackage{ID: packageID}, nil
+		}
+	}
+	return nil, notFound("package", packageID)
+}
+
+// GetLogs returns the seeded logs. The fake does not associate logs with a
+// project, so all logs of the given type are returned.
+func (f *FakeClient) GetLogs(projectID string, logType string) ([]ProjectLog, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var logs []ProjectLog
+	for _, log := range f.Logs {
+		if logType == "" || log.LogType == logType {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
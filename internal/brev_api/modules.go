@@ -33,7 +33,7 @@ func (a *Agent) GetModules() (*Modules, error) {
 			{"Authorization", "Bearer " + a.Key.AccessToken},
 		},
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +61,7 @@ func (a *Agent) UpdateModule(moduleID string, source string) (*ResponseUpdateMod
 			"source": source,
 		},
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
 		return nil, err
 	}
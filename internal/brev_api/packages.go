@@ -3,6 +3,7 @@ package brev_api
 import (
 	"fmt"
 
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
 	"github.com/brevdev/brev-go-cli/internal/requests"
 )
 
@@ -40,9 +41,9 @@ func (a *Agent) GetPackages(projectID string) ([]ProjectPackage, error) {
 			{"Authorization", "Bearer " + a.Key.AccessToken},
 		},
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get packages: %s", err)
+		return nil, fmt.Errorf("failed to get packages: %w", err)
 	}
 
 	var payload ProjectPackages
@@ -54,7 +55,21 @@ func (a *Agent) GetPackages(projectID string) ([]ProjectPackage, error) {
 	return payload.Packages, nil
 }
 
-func (a *Agent) AddPackage(projectID string, name string) (*ResponseAddPackage, error) {
+// AddPackage installs the named package into the project. The version is either
+// exact, as in 1.21.0, or a pip version specifier, as in >=2.25,<3. An empty
+// version installs the latest release.
+//
+// The version is sent in the same field the API returns it in on every package,
+// see ProjectPackage. A package added with a version must come back with one,
+// otherwise the API dropped the field and a PackageVersionError is returned.
+func (a *Agent) AddPackage(projectID string, name string, version string) (*ResponseAddPackage, error) {
+	payload := map[string]string{
+		"name":       name,
+		"project_id": projectID,
+	}
+	if version != "" {
+		payload["version"] = version
+	}
 	request := requests.RESTRequest{
 		Method:   "POST",
 		Endpoint: brevEndpoint("package"),
@@ -64,23 +79,23 @@ func (a *Agent) AddPackage(projectID string, name string) (*ResponseAddPackage,
 		Headers: []requests.Header{
 			{"Authorization", "Bearer " + a.Key.AccessToken},
 		},
-		Payload: map[string]string{
-			"name":       name,
-			"project_id": projectID,
-		},
+		Payload: payload,
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create package: %s", err)
+		return nil, fmt.Errorf("failed to create package: %w", err)
 	}
 
-	var payload ResponseAddPackage
-	err = response.UnmarshalPayload(&payload)
+	var added ResponseAddPackage
+	err = response.UnmarshalPayload(&added)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deserialize response payload: %s", err)
 	}
+	if version != "" && added.Package.Version == "" {
+		return nil, &brev_errors.PackageVersionError{Name: name, Version: version}
+	}
 
-	return &payload, nil
+	return &added, nil
 }
 
 func (a *Agent) RemovePackage(packageID string) (*ResponseRemovePackage, error) {
@@ -94,7 +109,7 @@ func (a *Agent) RemovePackage(packageID string) (*ResponseRemovePackage, error)
 			{"Authorization", "Bearer " + a.Key.AccessToken},
 		},
 	}
-	response, err := request.SubmitStrict()
+	response, err := submitStrict(&request)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,49 @@
+package brev_api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/auth"
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/config"
+)
+
+func TestAddPackageVersion(t *testing.T) {
+	// echo tells the stub server whether to return the requested version
+	echo := true
+	var requested map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = nil
+		json.NewDecoder(r.Body).Decode(&requested)
+		added := ProjectPackage{Id: "pkg-1", Name: requested["name"], Status: "pending"}
+		if echo {
+			added.Version = requested["version"]
+		}
+		json.NewEncoder(w).Encode(ResponseAddPackage{Package: added})
+	}))
+	defer server.Close()
+	oldEndpoint := config.BrevAPIEndpoint
+	config.BrevAPIEndpoint = server.URL
+	defer func() { config.BrevAPIEndpoint = oldEndpoint }()
+
+	agent := &Agent{Key: &auth.CotterOauthToken{AccessToken: "token"}}
+	added, err := agent.AddPackage("proj-1", "numpy", "==1.21.0")
+	if err != nil || added.Package.Version != "==1.21.0" || requested["version"] != "==1.21.0" {
+		t.Errorf("AddPackage() = %+v, %v after sending %v, want the version sent and returned", added, err, requested)
+	}
+
+	if _, err := agent.AddPackage("proj-1", "numpy", ""); err != nil || requested["version"] != "" {
+		t.Errorf("AddPackage() without a version = %v after sending %v", err, requested)
+	}
+
+	echo = false
+	_, err = agent.AddPackage("proj-1", "numpy", "==1.21.0")
+	var versionErr *brev_errors.PackageVersionError
+	if !errors.As(err, &versionErr) {
+		t.Errorf("AddPackage() error = %v, want a PackageVersionError when the API drops the version", err)
+	}
+}
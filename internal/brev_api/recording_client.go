@@ -0,0 +1,191 @@
+package brev_api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CallRecorder is told about the requests a RecordingClient would have sent
+type CallRecorder interface {
+	RecordCall(method string, path string, detail string)
+}
+
+// Planner is implemented by clients which only plan changes, like RecordingClient
+type Planner interface {
+	// PlanPoll records polling the given path, which a command would do to wait for
+	// the results of its changes
+	PlanPoll(path string, detail string)
+}
+
+// RecordingClient reads through to another Client but only records the requests
+// which would change remote state, answering them as the API would. It backs
+// the --dry-run flag.
+type RecordingClient struct {
+	client   Client
+	recorder CallRecorder
+
+	mu     sync.Mutex
+	nextID int
+}
+
+var _ Client = (*RecordingClient)(nil)
+var _ Planner = (*RecordingClient)(nil)
+
+// NewRecordingClient returns a RecordingClient reading from client and recording to recorder
+func NewRecordingClient(client Client, recorder CallRecorder) *RecordingClient {
+	return &RecordingClient{client: client, recorder: recorder}
+}
+
+// PlanPoll implements Planner
+func (r *RecordingClient) PlanPoll(path string, detail string) {
+	r.recorder.RecordCall("poll", path, detail)
+}
+
+// newID returns a placeholder ID for a resource that was not created
+func (r *RecordingClient) newID(prefix string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	return fmt.Sprintf("dry-run-%s-%d", prefix, r.nextID)
+}
+
+func (r *RecordingClient) GetProjects() ([]Project, error) {
+	return r.client.GetProjects()
+}
+
+func (r *RecordingClient) CreateProject(name string) (*ResponseCreateProject, error) {
+	r.recorder.RecordCall("POST", "/_api/_project", "name="+name)
+
+	project := Project{Id: r.newID("proj"), Name: name}
+	return &ResponseCreateProject{
+		Project: project,
+		Module:  Module{Id: r.newID("mod"), Name: "shared", ProjectId: project.Id},
+	}, nil
+}
+
+func (r *RecordingClient) GetEndpoints() ([]Endpoint, error) {
+	return r.client.GetEndpoints()
+}
+
+func (r *RecordingClient) CreateEndpoint(name string, projectId string, methods []string, code string) (*ResponseUpdateEndpoint, error) {
+	if code == "" {
+		code = dummyCode
+	}
+	if len(methods) == 0 {
+		methods = InferMethods(code)
+	}
+	r.recorder.RecordCall("POST", "/_api/_endpoint", fmt.Sprintf(
+		"name=%s project_id=%s methods=%s code=%d bytes", name, projectId, strings.Join(methods, ","), len(code),
+	))
+
+	return &ResponseUpdateEndpoint{Endpoint: Endpoint{
+		Id:        r.newID("endpoint"),
+		Name:      name,
+		ProjectId: projectId,
+		Methods:   methods,
+		Code:      code,
+		Uri:       "/" + name,
+	}}, nil
+}
+
+func (r *RecordingClient) UpdateEndpoint(endpointID string, updateRequest RequestUpdateEndpoint) (*ResponseUpdateEndpoint, error) {
+	r.recorder.RecordCall("PUT", "/_api/_endpoint/"+endpointID, fmt.Sprintf(
+		"name=%s methods=%s code=%d bytes", updateRequest.Name, strings.Join(updateRequest.Methods, ","), len(updateRequest.Code),
+	))
+
+	// answer with the current state of the endpoint, updated as requested
+	endpoint := Endpoint{Id: endpointID}
+	endpoints, err := r.client.GetEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range endpoints {
+		if v.Id == endpointID {
+			endpoint = v
+		}
+	}
+	if endpoint.Uri == "/"+endpoint.Name {
+		endpoint.Uri = "/" + updateRequest.Name
+	}
+	endpoint.Name = updateRequest.Name
+	endpoint.Methods = updateRequest.Methods
+	endpoint.Code = updateRequest.Code
+	return &ResponseUpdateEndpoint{Endpoint: endpoint}, nil
+}
+
+func (r *RecordingClient) RemoveEndpoint(endpointID string) (*ResponseRemoveEndpoint, error) {
+	r.recorder.RecordCall("DELETE", "/_api/_endpoint/"+endpointID, "")
+	return &ResponseRemoveEndpoint{ID: endpointID, Success: true}, nil
+}
+
+func (r *RecordingClient) GetModules() (*Modules, error) {
+	return r.client.GetModules()
+}
+
+func (r *RecordingClient) UpdateModule(moduleID string, source string) (*ResponseUpdateModule, error) {
+	r.recorder.RecordCall("PUT", "/_api/module/"+moduleID, fmt.Sprintf("source=%d bytes", len(source)))
+
+	module := Module{Id: moduleID}
+	modules, err := r.client.GetModules()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range modules.Modules {
+		if v.Id == moduleID {
+			module = v
+		}
+	}
+	module.Source = source
+	return &ResponseUpdateModule{Module: module}, nil
+}
+
+func (r *RecordingClient) GetVariables(projectID string) ([]ProjectVariable, error) {
+	return r.client.GetVariables(projectID)
+}
+
+// AddVariable records the variable without its value, which may be a secret
+func (r *RecordingClient) AddVariable(projectID string, name string, value string) (*ResponseAddVariable, error) {
+	r.recorder.RecordCall("POST", "/_api/variable", fmt.Sprintf("name=%s project_id=%s value=<hidden>", name, projectID))
+
+	return &ResponseAddVariable{Variable: ProjectVariable{
+		Id:        r.newID("var"),
+		Name:      name,
+		ProjectId: projectID,
+	}}, nil
+}
+
+func (r *RecordingClient) RemoveVariable(variableID string) (*ResponseRemoveVariable, error) {
+	r.recorder.RecordCall("DELETE", "/_api/variable/"+variableID, "")
+	return &ResponseRemoveVariable{ID: variableID}, nil
+}
+
+func (r *RecordingClient) GetPackages(projectID string) ([]ProjectPackage, error) {
+	return r.client.GetPackages(projectID)
+}
+
+func (r *RecordingClient) AddPackage(projectID string, name string, version string) (*ResponseAddPackage, error) {
+	detail := fmt.Sprintf("name=%s project_id=%s", name, projectID)
+	if version != "" {
+		detail += " version=" + version
+	}
+	r.recorder.RecordCall("POST", "/_api/package", detail)
+
+	return &ResponseAddPackage{Package: ProjectPackage{
+		Id:        r.newID("pkg"),
+		Name:      name,
+		ProjectId: projectID,
+		Status:    "pending",
+		Version:   version,
+	}}, nil
+}
+
+func (r *RecordingClient) RemovePackage(packageID string) (*ResponseRemovePackage, error) {
+	r.recorder.RecordCall("DELETE", "/_api/package/"+packageID, "")
+	return &ResponseRemovePackage{ID: packageID}, nil
+}
+
+func (r *RecordingClient) GetLogs(projectID string, logType string) ([]ProjectLog, error) {
+	return r.client.GetLogs(projectID, logType)
+}
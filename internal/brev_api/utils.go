@@ -21,10 +21,7 @@ func brevEndpoint(resource string) string {
 
 // Example usage
 /*
-	token, _ := auth.GetToken()
-	brevAgent := brev_api.Agent{
-		Key: token,
-	}
+	brevAgent, _ := brev_api.NewClient()
 
 	endpointsResponse, _ := brevAgent.GetEndpoints()
 	fmt.Println(endpointsResponse)
@@ -48,6 +45,20 @@ func GetActiveProject() (*Project, error) {
 	return &project, nil
 }
 
+// GetActiveEndpointNames returns the names of the endpoints of the active project, as
+// stored locally, for shell completion
+func GetActiveEndpointNames() []string {
+	var endpoints []Endpoint
+	files.ReadJSON(files.GetEndpointsPath(), &endpoints)
+
+	var epNames []string
+	for _, v := range endpoints {
+		epNames = append(epNames, v.Name)
+	}
+
+	return epNames
+}
+
 func IsInProjectDirectory() (bool, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -68,7 +79,7 @@ func IsInProjectDirectory() (bool, error) {
 	return false, nil
 }
 
-func CheckOutsideBrevErrorMessage(t *terminal.Terminal) (bool, error) {
+func CheckOutsideBrevErrorMessage(t *terminal.Terminal, newClient ClientFactory) (bool, error) {
 	isInProjectDirectory, err := IsInProjectDirectory()
 	if err != nil {
 		return false, nil
@@ -91,15 +102,12 @@ func CheckOutsideBrevErrorMessage(t *terminal.Terminal) (bool, error) {
 		// If no directories, check if they have some remote.
 
 		// Get Projects
-		token, err := auth.GetToken()
+		client, err := newClient()
 		if err != nil {
 			t.Errprint(err, "Failed to retrieve auth token")
 			return false, err
 		}
-		brevAgent := Agent{
-			Key: token,
-		}
-		rawProjects, err := brevAgent.GetProjects()
+		rawProjects, err := client.GetProjects()
 		if err != nil {
 			t.Errprint(err, "Failed to get projects")
 			return false, err
@@ -1,15 +1,18 @@
 package brev_ctx
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 
-	"github.com/brevdev/brev-go-cli/internal/auth"
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_errors"
 	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
 )
 
 const (
@@ -18,6 +21,7 @@ const (
 
 	localProjectsFile        = "projects.json"
 	localEndpointsFile       = "endpoints.json"
+	localManifestFile        = "manifest.json"
 	globalActiveProjectsFile = "active_projects.json"
 )
 
@@ -36,7 +40,7 @@ type LocalContext struct{}
 
 // RemoteContext encapsulates the remote Brev state corresponding to the authorized user
 type RemoteContext struct {
-	agent *brev_api.Agent
+	client brev_api.Client
 }
 
 type GetEndpointsOptions struct {
@@ -68,8 +72,16 @@ type GetPackagesOptions struct {
 	Name string
 }
 
-// New instantiates a new instance of a BrevContext
-func New() (*BrevContext, error) {
+// New instantiates a new instance of a BrevContext, using the given factory to
+// construct the client backing its RemoteContext.
+//
+// Example usage:
+//   // talk to the Brev API
+//   brevCtx, err := New(brev_api.NewClient)
+//
+//   // talk to an in-memory fake
+//   brevCtx, err := New(brev_api.NewFakeClient().Factory())
+func New(newClient brev_api.ClientFactory) (*BrevContext, error) {
 	local, err := NewLocal()
 	if err != nil {
 		return nil, fmt.Errorf("could not instantiate local context: %s", err)
@@ -78,7 +90,7 @@ func New() (*BrevContext, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not instantiate global context: %s", err)
 	}
-	remote, err := NewRemote()
+	remote, err := NewRemote(newClient)
 	if err != nil {
 		return nil, err
 	}
@@ -114,11 +126,33 @@ func (c *GlobalContext) GetProjectPaths() ([]string, error) {
 	return paths, nil
 }
 
+// GetProjectFiles returns the resolver for the code files of the project containing
+// the current working directory, laid out as configured in its .brev/layout.json
+func (c *GlobalContext) GetProjectFiles() (*layout.Paths, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine working directory: %w", err)
+	}
+
+	paths, err := c.GetProjectPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var root string
+	for _, v := range paths {
+		if strings.Contains(cwd, v) {
+			root = v
+		}
+	}
+	return layout.New(root)
+}
+
 // SetProjectPath sets the given path into the global list of project filepaths
 func (c *GlobalContext) SetProjectPath(path string) error {
 	paths, err := c.GetProjectPaths()
 	if err != nil {
-		return fmt.Errorf("failed to get project paths: %s", err)
+		return fmt.Errorf("failed to get project paths: %w", err)
 	}
 
 	for _, savedPath := range paths {
@@ -252,16 +286,66 @@ func (c *LocalContext) SetEndpoint(endpoint brev_api.Endpoint) error {
 	return nil
 }
 
-// NewRemote returns a new instance of a RemoteContext, with an initialized auth token.
-// Further calls to NewRemote will re-authenticate and store new auth tokens.
-func NewRemote() (*RemoteContext, error) {
-	token, err := auth.GetToken()
+// Manifest maps the file names of a project to a hash of their contents as of the
+// last push or pull
+type Manifest map[string]string
+
+// HashContents returns the hash recorded in a Manifest for the given file contents
+func HashContents(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
+
+// Unchanged reports whether the contents match what the manifest recorded for the file
+func (m Manifest) Unchanged(fileName string, contents string) bool {
+	hash, ok := m[fileName]
+	return ok && hash == HashContents(contents)
+}
+
+// Record stores the hash of the file's contents in the manifest
+func (m Manifest) Record(fileName string, contents string) {
+	m[fileName] = HashContents(contents)
+}
+
+// GetManifest returns the manifest of the project corresponding to the current working
+// directory. An empty manifest is returned if the project was never pushed or pulled.
+func (c *LocalContext) GetManifest() (Manifest, error) {
+	manifest := make(Manifest)
+
+	manifestFileExists, err := files.Exists(getLocalManifestPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from %s: %s", getLocalManifestPath(), err)
+	}
+	if !manifestFileExists {
+		return manifest, nil
+	}
+
+	err = files.ReadJSON(getLocalManifestPath(), &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from %s: %s", getLocalManifestPath(), err)
+	}
+	return manifest, nil
+}
+
+// SetManifest stores the manifest of the project corresponding to the current working directory
+func (c *LocalContext) SetManifest(manifest Manifest) error {
+	err := files.OverwriteJSON(getLocalManifestPath(), manifest)
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %s", getLocalManifestPath(), err)
+	}
+	return nil
+}
+
+// NewRemote returns a new instance of a RemoteContext backed by a client from the given factory.
+// For brev_api.NewClient, further calls to NewRemote will re-authenticate and store new auth tokens.
+func NewRemote(newClient brev_api.ClientFactory) (*RemoteContext, error) {
+	client, err := newClient()
 	if err != nil {
 		return nil, err
 	}
 
 	return &RemoteContext{
-		agent: &brev_api.Agent{Key: token},
+		client: client,
 	}, nil
 }
 
@@ -269,7 +353,7 @@ func NewRemote() (*RemoteContext, error) {
 // struct may be provided to filter the results.
 //
 // Example usage:
-//   remote, _ := NewRemote()
+//   remote, _ := NewRemote(brev_api.NewClient)
 //
 //   // no filtering
 //   projects, err := remote.GetProjects(nil)
@@ -284,9 +368,9 @@ func NewRemote() (*RemoteContext, error) {
 //       ID: "abc123def456",
 //   })
 func (c *RemoteContext) GetProjects(options *GetProjectsOptions) ([]brev_api.Project, error) {
-	projects, err := c.agent.GetProjects()
+	projects, err := c.client.GetProjects()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get endpoints: %s", err)
+		return nil, fmt.Errorf("failed to get endpoints: %w", err)
 	}
 
 	if options == nil {
@@ -306,14 +390,30 @@ func (c *RemoteContext) GetProjects(options *GetProjectsOptions) ([]brev_api.Pro
 	return filteredProjects, nil
 }
 
+// Planner returns the client's Planner during a dry run, and false when changes
+// take place
+func (c *RemoteContext) Planner() (brev_api.Planner, bool) {
+	planner, ok := c.client.(brev_api.Planner)
+	return planner, ok
+}
+
+// CreateProject creates a new remote project, along with its shared module, for the context user.
+func (c *RemoteContext) CreateProject(name string) (*brev_api.Project, error) {
+	response, err := c.client.CreateProject(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	return &response.Project, nil
+}
+
 func (c *RemoteContext) GetModule(options *GetModulesOptions) (*brev_api.Module, error) {
-	modules, err := c.agent.GetModules()
+	modules, err := c.client.GetModules()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get module: %s", err)
+		return nil, fmt.Errorf("failed to get module: %w", err)
 	}
 
 	if options == nil {
-		return nil, fmt.Errorf("Project ID is required %s", err)
+		return nil, errors.New("project ID is required")
 	}
 
 	var module brev_api.Module
@@ -323,31 +423,33 @@ func (c *RemoteContext) GetModule(options *GetModulesOptions) (*brev_api.Module,
 		}
 	}
 	if module == (brev_api.Module{}) {
-		return nil, fmt.Errorf("Invalid Project ID %s", err)
+		return nil, fmt.Errorf("no module found for project %s", options.ProjectID)
 	}
 
 	return &module, nil
 }
 
-func (c *RemoteContext) SetModule(options *SetModulesOptions) (*brev_api.Module, error) {
+// SetModule updates the source of the remote module. The response carries the updated
+// module along with the output produced while loading it.
+func (c *RemoteContext) SetModule(options *SetModulesOptions) (*brev_api.ResponseUpdateModule, error) {
 
 	if options == nil {
-		return nil, fmt.Errorf("Project ID is required %s")
+		return nil, errors.New("project ID is required")
 	}
 
-	module, err := c.agent.UpdateModule(options.ModuleID, options.Source)
+	module, err := c.client.UpdateModule(options.ModuleID, options.Source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update module: %s", err)
+		return nil, fmt.Errorf("failed to update module: %w", err)
 	}
 
-	return &module.Module, nil
+	return module, nil
 }
 
 // GetEndpoints retrieves remote endpoints for the context user. An optional GetEndpointsOptions
 // struct may be provided to filter the results.
 //
 // Example usage:
-//   remote, _ := NewRemote()
+//   remote, _ := NewRemote(brev_api.NewClient)
 //
 //   // no filtering
 //   endpoints, err := remote.GetEndpoints(nil)
@@ -362,9 +464,9 @@ func (c *RemoteContext) SetModule(options *SetModulesOptions) (*brev_api.Module,
 //       ProjectID: "abc123def456",
 //   })
 func (c *RemoteContext) GetEndpoints(options *GetEndpointsOptions) ([]brev_api.Endpoint, error) {
-	endpoints, err := c.agent.GetEndpoints()
+	endpoints, err := c.client.GetEndpoints()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get endpoints: %s", err)
+		return nil, fmt.Errorf("failed to get endpoints: %w", err)
 	}
 
 	if options == nil {
@@ -391,19 +493,19 @@ func (c *RemoteContext) GetEndpoints(options *GetEndpointsOptions) ([]brev_api.E
 // endpoint struct.
 func (c *RemoteContext) SetEndpoint(endpoint brev_api.Endpoint) (*brev_api.Endpoint, error) {
 	if endpoint.Id == "" {
-		response, err := c.agent.CreateEndpoint(endpoint.Name, endpoint.ProjectId)
+		response, err := c.client.CreateEndpoint(endpoint.Name, endpoint.ProjectId, endpoint.Methods, endpoint.Code)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create endpoint: %s", err)
+			return nil, fmt.Errorf("failed to create endpoint: %w", err)
 		}
 		return &response.Endpoint, nil
 	} else {
-		response, err := c.agent.UpdateEndpoint(endpoint.Id, brev_api.RequestUpdateEndpoint{
+		response, err := c.client.UpdateEndpoint(endpoint.Id, brev_api.RequestUpdateEndpoint{
 			Name:    endpoint.Name,
 			Methods: endpoint.Methods,
 			Code:    endpoint.Code,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to update endpoint: %s", err)
+			return nil, fmt.Errorf("failed to update endpoint: %w", err)
 		}
 		return &response.Endpoint, nil
 	}
@@ -411,9 +513,9 @@ func (c *RemoteContext) SetEndpoint(endpoint brev_api.Endpoint) (*brev_api.Endpo
 
 // DeleteEndpoint removes the remote endpoint with the given ID.
 func (c *RemoteContext) DeleteEndpoint(epId string) error {
-	_, err := c.agent.RemoveEndpoint(epId)
+	_, err := c.client.RemoveEndpoint(epId)
 	if err != nil {
-		return fmt.Errorf("failed to delete endpoint: %s", err)
+		return fmt.Errorf("failed to delete endpoint: %w", err)
 	}
 	return nil
 }
@@ -422,7 +524,7 @@ func (c *RemoteContext) DeleteEndpoint(epId string) error {
 // struct may be provided to filter the results.
 //
 // Example usage:
-//   remote, _ := NewRemote()
+//   remote, _ := NewRemote(brev_api.NewClient)
 //
 //   // no filtering
 //   endpoints, err := remote.GetVariables(nil)
@@ -432,9 +534,9 @@ func (c *RemoteContext) DeleteEndpoint(epId string) error {
 //       Name: "foobarbaz",
 //   })
 func (c *RemoteContext) GetVariables(project brev_api.Project, options *GetVariablesOptions) ([]brev_api.ProjectVariable, error) {
-	variables, err := c.agent.GetVariables(project.Id)
+	variables, err := c.client.GetVariables(project.Id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get project variables: %s", err)
+		return nil, fmt.Errorf("failed to get project variables: %w", err)
 	}
 
 	if options == nil {
@@ -453,17 +555,26 @@ func (c *RemoteContext) GetVariables(project brev_api.Project, options *GetVaria
 
 // SetVariable sets the given name/value pair for the given project.
 func (c *RemoteContext) SetVariable(project brev_api.Project, name string, value string) (*brev_api.ProjectVariable, error) {
-	response, err := c.agent.AddVariable(project.Id, name, value)
+	response, err := c.client.AddVariable(project.Id, name, value)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set project variable: %s", err)
+		return nil, fmt.Errorf("failed to set project variable: %w", err)
 	}
 	return &response.Variable, nil
 }
 
+// DeleteVariable removes the remote variable with the given ID.
+func (c *RemoteContext) DeleteVariable(variableID string) error {
+	_, err := c.client.RemoveVariable(variableID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project variable: %w", err)
+	}
+	return nil
+}
+
 func (c *RemoteContext) GetPackages(project brev_api.Project, options *GetPackagesOptions) ([]brev_api.ProjectPackage, error) {
-	packages, err := c.agent.GetPackages(project.Id)
+	packages, err := c.client.GetPackages(project.Id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve project packages: %s", err)
+		return nil, fmt.Errorf("failed to retrieve project packages: %w", err)
 	}
 
 	if options == nil {
@@ -479,14 +590,34 @@ func (c *RemoteContext) GetPackages(project brev_api.Project, options *GetPackag
 	return filteredPackages, nil
 }
 
-func (c *RemoteContext) SetPackage(project brev_api.Project, name string) (*brev_api.ProjectPackage, error) {
-	response, err := c.agent.AddPackage(project.Id, name)
+// SetPackage installs the named package into the given project. The version is
+// exact or a pip version specifier, and an empty one installs the latest release.
+func (c *RemoteContext) SetPackage(project brev_api.Project, name string, version string) (*brev_api.ProjectPackage, error) {
+	response, err := c.client.AddPackage(project.Id, name, version)
 	if err != nil {
-		return nil, fmt.Errorf("failed to add project package: %s", err)
+		return nil, fmt.Errorf("failed to add project package: %w", err)
 	}
 	return &response.Package, nil
 }
 
+// DeletePackage removes the remote package with the given ID.
+func (c *RemoteContext) DeletePackage(packageID string) error {
+	_, err := c.client.RemovePackage(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to remove project package: %w", err)
+	}
+	return nil
+}
+
+// GetLogs retrieves remote logs of the given type for the given project.
+func (c *RemoteContext) GetLogs(project brev_api.Project, logType string) ([]brev_api.ProjectLog, error) {
+	logs, err := c.client.GetLogs(project.Id, logType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project logs: %w", err)
+	}
+	return logs, nil
+}
+
 func getGlobalActiveProjectsPath() string {
 	homeDir := files.GetHomeDir()
 	return fmt.Sprintf("%s/%s/%s", homeDir, globalBrevDirectory, globalActiveProjectsFile)
@@ -501,3 +632,8 @@ func getLocalEndpointsPath() string {
 	cwd, _ := os.Getwd()
 	return fmt.Sprintf("%s/%s/%s", cwd, localBrevDirectory, localEndpointsFile)
 }
+
+func getLocalManifestPath() string {
+	cwd, _ := os.Getwd()
+	return fmt.Sprintf("%s/%s/%s", cwd, localBrevDirectory, localManifestFile)
+}
@@ -1,5 +1,11 @@
 package brev_errors
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type BrevError interface {
 
 	// Error returns a user-facing string explaining the error
@@ -22,7 +28,7 @@ func (e *SuppressedError) Error() string {
 type CredentialsFileNotFound struct{}
 
 func (e *CredentialsFileNotFound) Directive() string {
-	return "run `brev login`"
+	return "run `brev login`, or set BREV_TOKEN"
 }
 
 func (e *CredentialsFileNotFound) Error() string {
@@ -72,7 +78,7 @@ func (e *InitExistingEndpointsFile) Error() string {
 type CotterClientError struct{}
 
 func (e *CotterClientError) Directive() string {
-	return "run `brev login`"
+	return "run `brev login`, or set BREV_TOKEN"
 }
 
 func (e *CotterClientError) Error() string {
@@ -88,3 +94,166 @@ func (e *CotterServerError) Directive() string {
 func (e *CotterServerError) Error() string {
 	return "internal error reported by auth server"
 }
+
+// APIError is a structured error decoded from a failed Brev API response
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestURI string
+}
+
+func (e *APIError) Directive() string {
+	if e.StatusCode >= 500 {
+		return "the Brev API is having trouble; wait a moment and try again"
+	}
+	return "check the command's arguments and try again, or run with --verbose for details"
+}
+
+func (e *APIError) Error() string {
+	description := e.Message
+	if description == "" {
+		description = "request failed"
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("%s (%s, status %d)", description, e.Code, e.StatusCode)
+	}
+	return fmt.Sprintf("%s (status %d)", description, e.StatusCode)
+}
+
+type UnauthorizedError struct {
+	APIError
+}
+
+func (e *UnauthorizedError) Directive() string {
+	return "run `brev login`, or set BREV_TOKEN"
+}
+
+func (e *UnauthorizedError) Error() string {
+	return "not authorized by the Brev API: " + e.APIError.Error()
+}
+
+type RemoteNotFoundError struct {
+	APIError
+}
+
+func (e *RemoteNotFoundError) Directive() string {
+	return "run `brev status` to check the project, or remove the local .brev directory and run `brev clone`"
+}
+
+func (e *RemoteNotFoundError) Error() string {
+	return "project was deleted remotely: " + e.APIError.Error()
+}
+
+type NameConflictError struct {
+	APIError
+}
+
+func (e *NameConflictError) Directive() string {
+	return "choose a different name and try again"
+}
+
+func (e *NameConflictError) Error() string {
+	return "name already in use: " + e.APIError.Error()
+}
+
+// SyncConflictError reports project files which changed both locally and remotely
+// since the last push or pull
+type SyncConflictError struct {
+	Files []string
+}
+
+func (e *SyncConflictError) Directive() string {
+	return "rerun with --ours to keep your local changes, --theirs to take the remote ones, or --markers to merge them by hand"
+}
+
+func (e *SyncConflictError) Error() string {
+	return fmt.Sprintf("local and remote changes conflict in %s", strings.Join(e.Files, ", "))
+}
+
+// PackageNotFoundError reports packages which are not installed in the project
+type PackageNotFoundError struct {
+	Names   []string
+	Project string
+}
+
+func (e *PackageNotFoundError) Directive() string {
+	return "run `brev package list` to see the packages of the project"
+}
+
+func (e *PackageNotFoundError) Error() string {
+	return fmt.Sprintf("project %s has no package %s", e.Project, strings.Join(e.Names, ", "))
+}
+
+// PackageVersionError reports a package which the Brev API installed without the
+// requested version
+type PackageVersionError struct {
+	Name    string
+	Version string
+}
+
+func (e *PackageVersionError) Directive() string {
+	return fmt.Sprintf("run `brev package remove %s`, then add it without a version", e.Name)
+}
+
+func (e *PackageVersionError) Error() string {
+	return fmt.Sprintf("the Brev API added %s without the requested version %s", e.Name, e.Version)
+}
+
+// LoginCallbackError is an error the auth server redirected to the login callback with
+type LoginCallbackError struct {
+	Code        string
+	Description string
+}
+
+func (e *LoginCallbackError) Directive() string {
+	return "run `brev login` to try again"
+}
+
+func (e *LoginCallbackError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("login failed: %s (%s)", e.Description, e.Code)
+	}
+	return "login failed: " + e.Code
+}
+
+// LoginStateMismatchError reports a login callback which was not for this login attempt
+type LoginStateMismatchError struct{}
+
+func (e *LoginStateMismatchError) Directive() string {
+	return "run `brev login` again and log in with the link it opens"
+}
+
+func (e *LoginStateMismatchError) Error() string {
+	return "login callback does not belong to this login"
+}
+
+// LoginTimeoutError reports a login which was not finished in the browser in time
+type LoginTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *LoginTimeoutError) Directive() string {
+	return fmt.Sprintf("run `brev login` and finish logging in within %s", e.Timeout)
+}
+
+func (e *LoginTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s waiting for the browser login", e.Timeout)
+}
+
+// LoginServerError reports that the local server awaiting the login callback could not start
+type LoginServerError struct {
+	Err error
+}
+
+func (e *LoginServerError) Directive() string {
+	return "run `brev login --no-browser` to log in without the local server"
+}
+
+func (e *LoginServerError) Error() string {
+	return "failed to start the login callback server: " + e.Err.Error()
+}
+
+func (e *LoginServerError) Unwrap() error {
+	return e.Err
+}
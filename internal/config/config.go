@@ -9,6 +9,7 @@ var (
 	Version         = "unknown"
 	CotterAPIKey    = "unknown"
 	BrevAPIEndpoint = "https://app.brev.dev"
+	PyPIEndpoint    = "https://pypi.org"
 )
 
 func Init() {
@@ -26,3 +27,7 @@ func GetCotterAPIKey() string {
 func GetBrevAPIEndpoint() string {
 	return BrevAPIEndpoint
 }
+
+func GetPyPIEndpoint() string {
+	return PyPIEndpoint
+}
@@ -0,0 +1,141 @@
+package dryrun
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// Step is a single change a dry run would have made
+type Step struct {
+	// Action is the HTTP method of a request, or one of write, rename and delete for a file
+	Action string
+	Target string
+	Detail string
+}
+
+// Plan collects, in order, the requests and file changes of a command run with
+// --dry-run. Once started, clients from Factory only record their changes, and
+// so does the files package.
+//
+// Example usage:
+//   plan := dryrun.NewPlan()
+//   newClient := plan.Factory(brev_api.NewClient)
+//   plan.Start()
+//   ... run the command with newClient ...
+//   plan.Print(t)
+type Plan struct {
+	mu      sync.Mutex
+	started bool
+	steps   []Step
+}
+
+// NewPlan returns an empty Plan which has not been started
+func NewPlan() *Plan {
+	return &Plan{}
+}
+
+// Start makes the Plan record changes instead of letting them take place
+func (p *Plan) Start() {
+	p.mu.Lock()
+	p.started = true
+	p.mu.Unlock()
+
+	files.SetRecorder(p)
+}
+
+// Started reports whether the Plan is recording
+func (p *Plan) Started() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.started
+}
+
+// Factory wraps the given factory. Its clients record their changes to the Plan
+// once it is started and are returned unchanged otherwise.
+func (p *Plan) Factory(newClient brev_api.ClientFactory) brev_api.ClientFactory {
+	return func() (brev_api.Client, error) {
+		if !p.Started() {
+			return newClient()
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return nil, err
+		}
+		return brev_api.NewRecordingClient(client, p), nil
+	}
+}
+
+// Steps returns the recorded changes in the order they would have been made
+func (p *Plan) Steps() []Step {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Step(nil), p.steps...)
+}
+
+func (p *Plan) add(step Step) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.steps = append(p.steps, step)
+}
+
+// RecordCall implements brev_api.CallRecorder
+func (p *Plan) RecordCall(method string, path string, detail string) {
+	p.add(Step{Action: method, Target: path, Detail: detail})
+}
+
+// RecordWrite implements files.Recorder
+func (p *Plan) RecordWrite(path string, size int) {
+	p.add(Step{Action: "write", Target: displayPath(path), Detail: fmt.Sprintf("%d bytes", size)})
+}
+
+// RecordRename implements files.Recorder
+func (p *Plan) RecordRename(from string, to string) {
+	p.add(Step{Action: "rename", Target: displayPath(from), Detail: "to " + displayPath(to)})
+}
+
+// RecordDelete implements files.Recorder
+func (p *Plan) RecordDelete(path string) {
+	p.add(Step{Action: "delete", Target: displayPath(path)})
+}
+
+// Print lists the recorded changes
+func (p *Plan) Print(t *terminal.Terminal) {
+	steps := p.Steps()
+	if len(steps) == 0 {
+		t.Vprint(t.Green("\nDry run: nothing would change"))
+		return
+	}
+
+	t.Vprint(t.Yellow("\nDry run: nothing was changed. The command would have made %d changes:", len(steps)))
+	for i, step := range steps {
+		line := fmt.Sprintf("%3d. %-7s %s", i+1, step.Action, step.Target)
+		if step.Detail != "" {
+			line += "  " + step.Detail
+		}
+		t.Vprint(line)
+	}
+}
+
+// displayPath shortens paths below the working directory
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
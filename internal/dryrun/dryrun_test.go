@@ -0,0 +1,116 @@
+package dryrun
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/parallel"
+)
+
+func TestPlan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "brev-dryrun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fake := brev_api.NewFakeClient()
+	project := fake.AddProject("project")
+	fake.AddPackage(project.Id, "numpy", "")
+
+	plan := NewPlan()
+	newClient := plan.Factory(fake.Factory())
+	plan.Start()
+	defer files.SetRecorder(nil)
+
+	client, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packages, err := client.GetPackages(project.Id)
+	if err != nil || len(packages) != 1 {
+		t.Fatalf("GetPackages() = %v, %v, want the package of the fake", packages, err)
+	}
+	added, err := client.AddPackage(project.Id, "requests", "2.25.1")
+	if err != nil || added.Package.Name != "requests" {
+		t.Fatalf("AddPackage() = %v, %v, want a planned package", added, err)
+	}
+	client.AddVariable(project.Id, "API_KEY", "secret")
+	client.RemovePackage(packages[0].Id)
+	path := filepath.Join(dir, "hello.py")
+	files.OverwriteString(path, "hello")
+	files.DeleteFile(path)
+
+	if len(fake.Packages) != 1 || len(fake.Variables) != 0 {
+		t.Errorf("dry run changed the remote state: packages %v, variables %v", fake.Packages, fake.Variables)
+	}
+	if exists, _ := files.Exists(path); exists {
+		t.Errorf("dry run wrote %s", path)
+	}
+
+	want := []Step{
+		{Action: "POST", Target: "/_api/package", Detail: "name=requests project_id=" + project.Id + " version=2.25.1"},
+		{Action: "POST", Target: "/_api/variable", Detail: "name=API_KEY project_id=" + project.Id + " value=<hidden>"},
+		{Action: "DELETE", Target: "/_api/package/" + packages[0].Id},
+		{Action: "write", Target: path, Detail: "5 bytes"},
+		{Action: "delete", Target: path},
+	}
+	if got := plan.Steps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Steps() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanNotStarted(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project := fake.AddProject("project")
+
+	plan := NewPlan()
+	client, err := plan.Factory(fake.Factory())()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.AddPackage(project.Id, "requests", "")
+
+	if len(fake.Packages) != 1 || len(plan.Steps()) != 0 {
+		t.Errorf("a plan which was not started recorded changes instead of making them")
+	}
+}
+
+func TestPlanConcurrentClients(t *testing.T) {
+	dir, err := ioutil.TempDir("", "brev-dryrun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fake := brev_api.NewFakeClient()
+	plan := NewPlan()
+	newClient := plan.Factory(fake.Factory())
+	plan.Start()
+	defer files.SetRecorder(nil)
+
+	// workers create clients while others write, as parallel.Run does
+	err = parallel.Run(20, 4, func(i int) error {
+		if i%2 == 0 {
+			_, err := newClient()
+			return err
+		}
+		return files.OverwriteString(filepath.Join(dir, fmt.Sprintf("%d.py", i)), "hello")
+	}, nil)
+	if err != nil {
+		t.Fatalf("parallel.Run() returned error: %v", err)
+	}
+
+	if written, _ := ioutil.ReadDir(dir); len(written) != 0 {
+		t.Errorf("dry run wrote %d files while clients were created", len(written))
+	}
+	if got := len(plan.Steps()); got != 10 {
+		t.Errorf("recorded %d steps, want the 10 writes", got)
+	}
+}
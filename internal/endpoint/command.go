@@ -1,11 +1,15 @@
 package endpoint
 
 import (
+	"errors"
+	"os"
+
 	"github.com/spf13/cobra"
 
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/cmdcontext"
-	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/logs"
+	"github.com/brevdev/brev-go-cli/internal/templates"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
@@ -13,19 +17,7 @@ func getSomeSetOfOptions(toComplete string) []string {
 	return []string{"opt1", "opt2"}
 }
 
-func getEpNames() []string {
-	var endpoints []brev_api.Endpoint
-	files.ReadJSON(files.GetEndpointsPath(), &endpoints)
-
-	var epNames []string
-	for _, v := range endpoints {
-		epNames = append(epNames, v.Name)
-	}
-
-	return epNames
-}
-
-func NewCmdEndpoint(t *terminal.Terminal) *cobra.Command {
+func NewCmdEndpoint(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:         "endpoint",
 		Annotations: map[string]string{"code": ""},
@@ -33,6 +25,7 @@ func NewCmdEndpoint(t *terminal.Terminal) *cobra.Command {
 		Long:        "Do any operation to your Brev endpoints.",
 		Example: `  brev endpoint add NewEp
   brev endpoint run NewEp
+  brev endpoint rename --from NewEp --to OtherEp
   brev endpoint remove NewEp`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
@@ -40,41 +33,61 @@ func NewCmdEndpoint(t *terminal.Terminal) *cobra.Command {
 				return err
 			}
 
-			_, err = brev_api.CheckOutsideBrevErrorMessage(t)
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
 			return err
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return []string{}, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveDefault
 		}}
 
-	cmd.AddCommand(newCmdAdd(t))
-	cmd.AddCommand(newCmdRemove(t))
-	cmd.AddCommand(newCmdRun(t))
-	// cmd.AddCommand(newCmdLog(context))
-	cmd.AddCommand(newCmdList(t))
+	cmd.AddCommand(newCmdAdd(t, newClient))
+	cmd.AddCommand(newCmdRemove(t, newClient))
+	cmd.AddCommand(newCmdRename(t, newClient))
+	cmd.AddCommand(newCmdMethods(t, newClient))
+	cmd.AddCommand(newCmdRun(t, newClient))
+	cmd.AddCommand(newCmdLog(t, newClient))
+	cmd.AddCommand(newCmdList(t, newClient))
+	cmd.AddCommand(newCmdStats(t, newClient))
 
 	return cmd
 }
 
-func newCmdAdd(t *terminal.Terminal) *cobra.Command {
+func newCmdAdd(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	var name string
+	var methods []string
+	var template string
 
 	cmd := &cobra.Command{
-		Use:     "add",
-		Short:   "Add an endpoint to your project.",
-		Long:    "Add an endpoint to your project. This will also create the file in your directory.",
-		Example: `  brev endpoint add NewEp`,
+		Use:   "add",
+		Short: "Add an endpoint to your project.",
+		Long: `Add an endpoint to your project. This will also create the file in your directory.
+
+The endpoint starts from the given template: one of the built-in templates crud,
+webhook, cron and empty, or your own .py files in ~/.brev/templates/ or in the
+project's .brev/templates/. Templates may use {{.Endpoint}}, {{.Project}} and
+{{.Domain}}, which are filled in with the endpoint name, project name and
+project domain.`,
+		Example: `  brev endpoint add NewEp
+  brev endpoint add NewEp --methods GET,POST
+  brev endpoint add Items --template crud`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return addEndpoint(name, t)
+			return addEndpoint(name, methods, template, t, newClient)
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the endpoint")
+	cmd.Flags().StringSliceVarP(&methods, "methods", "m", nil, "HTTP methods the endpoint serves, defaults to those its code handles")
+	cmd.RegisterFlagCompletionFunc("methods", completeMethods)
+	cmd.Flags().StringVarP(&template, "template", "t", "", "template the endpoint code starts from")
+	cmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cwd, _ := os.Getwd()
+		return templates.Names(cwd), cobra.ShellCompDirectiveNoSpace
+	})
 
 	return cmd
 }
 
-func newCmdRemove(t *terminal.Terminal) *cobra.Command {
+func newCmdRemove(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	var name string
 
 	cmd := &cobra.Command{
@@ -83,18 +96,85 @@ func newCmdRemove(t *terminal.Terminal) *cobra.Command {
 		Long:    "Remove an endpoint from your project. This will also remove the file from your directory.",
 		Example: `  brev endpoint remove NewEp`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return removeEndpoint(name, t)
+			return removeEndpoint(name, t, newClient)
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the endpoint")
 	cmd.MarkFlagRequired("name")
 	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return getEpNames(), cobra.ShellCompDirectiveNoSpace
+		return brev_api.GetActiveEndpointNames(), cobra.ShellCompDirectiveNoSpace
+	})
+	return cmd
+}
+
+func newCmdRename(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var from string
+	var to string
+
+	cmd := &cobra.Command{
+		Use:     "rename",
+		Short:   "Rename an endpoint of your project.",
+		Long:    "Rename an endpoint of your project. This will also rename the file in your directory.",
+		Example: `  brev endpoint rename --from NewEp --to OtherEp`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return renameEndpoint(from, to, t, newClient)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "current name of the endpoint")
+	cmd.MarkFlagRequired("from")
+	cmd.RegisterFlagCompletionFunc("from", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return brev_api.GetActiveEndpointNames(), cobra.ShellCompDirectiveNoSpace
 	})
+	cmd.Flags().StringVar(&to, "to", "", "new name of the endpoint")
+	cmd.MarkFlagRequired("to")
+	cmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoSpace
+	})
+
 	return cmd
 }
 
+func newCmdMethods(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var name string
+	var set []string
+	var infer bool
+
+	cmd := &cobra.Command{
+		Use:   "methods",
+		Short: "Show or change the HTTP methods of an endpoint.",
+		Long:  "Show the HTTP methods an endpoint serves, or change them with --set, or with --infer to match the get(), post(), put() and delete() functions of its file.",
+		Example: `  brev endpoint methods --name MyEp
+  brev endpoint methods --name MyEp --set GET,POST
+  brev endpoint methods --name MyEp --infer`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("set") && infer {
+				return errors.New("only one of --set and --infer may be given")
+			}
+			if !cmd.Flags().Changed("set") && !infer {
+				return showEndpointMethods(name, t, newClient)
+			}
+			return setEndpointMethods(name, set, infer, t, newClient)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the endpoint")
+	cmd.MarkFlagRequired("name")
+	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return brev_api.GetActiveEndpointNames(), cobra.ShellCompDirectiveNoSpace
+	})
+	cmd.Flags().StringSliceVar(&set, "set", nil, "HTTP methods the endpoint serves, replacing the current ones")
+	cmd.RegisterFlagCompletionFunc("set", completeMethods)
+	cmd.Flags().BoolVar(&infer, "infer", false, "set the methods to those handled by the endpoint's local file")
+
+	return cmd
+}
+
+func completeMethods(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return brev_api.HTTPMethods, cobra.ShellCompDirectiveNoSpace
+}
+
 type Method int
 
 const (
@@ -104,26 +184,32 @@ const (
 	DELETE
 )
 
-func newCmdRun(t *terminal.Terminal) *cobra.Command {
+func newCmdRun(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	var name string
 	var method string
 	var arg []string
 	var body string
+	var local bool
+	var python string
 
 	cmd := &cobra.Command{
-		Use:     "run",
-		Short:   "Run your endpoint",
-		Long:    "Run your endpoint  on the remote server. Similar to cURL and Postman, etc.",
-		Example: `  brev endpoint run MyEp`,
+		Use:   "run",
+		Short: "Run your endpoint",
+		Long:  "Run your endpoint on the remote server, or with --local against a local Python interpreter. Similar to cURL and Postman, etc.",
+		Example: `  brev endpoint run --name MyEp
+  brev endpoint run --name MyEp --local --method POST --body '{"a": 1}'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runEndpoint(name, method, arg, body, t)
+			if local {
+				return runEndpointLocally(name, method, arg, body, python, t, newClient)
+			}
+			return runEndpoint(name, method, arg, body, t, newClient)
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the endpoint")
 	cmd.MarkFlagRequired("name")
 	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return getEpNames(), cobra.ShellCompDirectiveNoSpace
+		return brev_api.GetActiveEndpointNames(), cobra.ShellCompDirectiveNoSpace
 	})
 	cmd.Flags().StringVarP(&method, "method", "r", "GET", "http request method")
 	cmd.MarkFlagRequired("method")
@@ -138,29 +224,38 @@ func newCmdRun(t *terminal.Terminal) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("body", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return nil, cobra.ShellCompDirectiveNoSpace
 	})
+	cmd.Flags().BoolVar(&local, "local", false, "run the local file with a local Python interpreter instead of pushing")
+	cmd.Flags().StringVar(&python, "python", "python3", "python interpreter used with --local")
 
 	return cmd
 }
 
-func newCmdLog(t *terminal.Terminal) *cobra.Command {
+func newCmdLog(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	var name string
+	var flags logs.Flags
 
 	cmd := &cobra.Command{
-		Use:     "log",
-		Short:   "Log an endpoint.",
-		Long:    "Get logs for any endpoint.",
-		Example: `  brev endpoint log NewEp`,
+		Use:   "log",
+		Short: "Log an endpoint.",
+		Long:  "Get request logs for any endpoint, optionally filtered and followed live.",
+		Example: `  brev endpoint log --name NewEp
+  brev endpoint log --name NewEp --status 5xx --since 1h --follow`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return logEndpoint(name, t)
+			return logEndpoint(name, flags, t, newClient)
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the endpoint")
+	cmd.MarkFlagRequired("name")
+	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return brev_api.GetActiveEndpointNames(), cobra.ShellCompDirectiveNoSpace
+	})
+	flags.Register(cmd)
 
 	return cmd
 }
 
-func newCmdList(t *terminal.Terminal) *cobra.Command {
+func newCmdList(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:     "list",
@@ -168,9 +263,43 @@ func newCmdList(t *terminal.Terminal) *cobra.Command {
 		Long:    "List endpoints in your project. This will print your URLs.",
 		Example: `  brev endpoint list`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listEndpoints(t)
+			return listEndpoints(t, newClient)
 		},
 	}
 
 	return cmd
 }
+
+func newCmdStats(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var name string
+	var since string
+	var until string
+	var logType string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show latency and error rates of your endpoints.",
+		Long:  "Show request counts, wall and CPU time percentiles, and 4xx/5xx rates per endpoint, computed from your project's logs.",
+		Example: `  brev endpoint stats
+  brev endpoint stats --since 1h --name MyEp
+  brev endpoint stats --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return endpointStats(name, since, until, logType, format, t, newClient)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "only show stats for this endpoint")
+	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return brev_api.GetActiveEndpointNames(), cobra.ShellCompDirectiveNoSpace
+	})
+	cmd.Flags().StringVar(&since, "since", "24h", "start of the window, as a duration ago (e.g. 1h) or a timestamp")
+	cmd.Flags().StringVar(&until, "until", "", "end of the window, as a duration ago (e.g. 10m) or a timestamp")
+	cmd.Flags().StringVar(&logType, "type", "request", "type of logs to fetch")
+	cmd.Flags().StringVar(&format, "format", "table", "output format: table or json")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json"}, cobra.ShellCompDirectiveNoSpace
+	})
+
+	return cmd
+}
@@ -6,18 +6,28 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
 	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/logs"
 	"github.com/brevdev/brev-go-cli/internal/requests"
+	"github.com/brevdev/brev-go-cli/internal/serve"
+	"github.com/brevdev/brev-go-cli/internal/templates"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func addEndpoint(name string, t *terminal.Terminal) error {
+func addEndpoint(name string, methods []string, templateName string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	methods, err := brev_api.ParseMethods(methods)
+	if err != nil {
+		return err
+	}
+
 	bar := t.NewProgressBar("\nAdding endpoint "+t.Yellow(name), func() {})
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -30,11 +40,41 @@ func addEndpoint(name string, t *terminal.Terminal) error {
 		return err
 	}
 
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	module, err := brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: project.Id})
+	if err != nil {
+		return err
+	}
+	err = paths.Layout.CheckEndpoint(name, module.Name)
+	if err != nil {
+		return err
+	}
+
+	// without a template the endpoint starts from the default starter code
+	var code string
+	if templateName != "" {
+		bar.Describe("Rendering template " + templateName)
+		code, err = templates.Render(templateName, paths.Root, templates.Vars{
+			Endpoint: name,
+			Project:  project.Name,
+			Domain:   project.Domain,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	// store endpoint in remote state
 	bar.Describe("Submitting request to create new endpoint")
 	endpoint, err := brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
 		ProjectId: project.Id,
 		Name:      name,
+		Methods:   methods,
+		Code:      code,
 	})
 	if err != nil {
 		return err
@@ -49,15 +89,20 @@ func addEndpoint(name string, t *terminal.Terminal) error {
 	}
 
 	// create the endpoint code file
-	cwd, err := os.Getwd()
+	err = files.OverwriteString(paths.Endpoint(endpoint.Name), endpoint.Code)
 	if err != nil {
-		t.Errprint(err, "\nFailed to determine working directory")
+		t.Errprint(err, "\nFailed to write endpoints to local file")
 		return err
 	}
 
-	err = files.OverwriteString(fmt.Sprintf("%s/%s.py", cwd, endpoint.Name), endpoint.Code)
+	// the file matches the deployed code, so later edits are pushed as local changes
+	manifest, err := brevCtx.Local.GetManifest()
+	if err != nil {
+		return err
+	}
+	manifest.Record(paths.Layout.EndpointFile(endpoint.Name), endpoint.Code)
+	err = brevCtx.Local.SetManifest(manifest)
 	if err != nil {
-		t.Errprint(err, "\nFailed to write endpoints to local file")
 		return err
 	}
 	bar.AdvanceTo(100)
@@ -67,11 +112,11 @@ func addEndpoint(name string, t *terminal.Terminal) error {
 	return nil
 }
 
-func removeEndpoint(name string, t *terminal.Terminal) error {
+func removeEndpoint(name string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
 	bar := t.NewProgressBar("Removing endpoint "+t.Yellow(name), func() {})
 	bar.AdvanceTo(30)
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -97,7 +142,12 @@ func removeEndpoint(name string, t *terminal.Terminal) error {
 	bar.Describe(t.Green("Endpoint ") + t.Yellow("%s", name) + t.Green(" deleted."))
 	bar.AdvanceTo(60)
 	// Remove the python file
-	files.DeleteFile(name + ".py")
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		t.Errprint(err, "Cannot delete Endpoint.")
+		return err
+	}
+	files.DeleteFile(paths.Endpoint(name))
 
 	// Update the endpoints.json
 	allEndpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
@@ -110,7 +160,7 @@ func removeEndpoint(name string, t *terminal.Terminal) error {
 	files.OverwriteJSON(files.GetEndpointsPath(), allEndpoints)
 	brevCtx.Local.SetEndpoints(allEndpoints)
 
-	bar.Describe(t.Green("File ") + t.Yellow("%s.py", name) + t.Green(" removed."))
+	bar.Describe(t.Green("File ") + t.Yellow("%s", paths.Layout.EndpointFile(name)) + t.Green(" removed."))
 	bar.AdvanceTo(100)
 
 	t.Vprint(t.Green("\nEndpoint ") + t.Yellow("%s", name) + t.Green(" removed from project ") + t.Yellow(project.Name) + " 🥞")
@@ -118,12 +168,258 @@ func removeEndpoint(name string, t *terminal.Terminal) error {
 	return nil
 }
 
-func runEndpoint(name string, method string, arg []string, jsonBody string, t *terminal.Terminal) error {
+func renameEndpoint(from string, to string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	if to == "" {
+		return errors.New("the new endpoint name may not be empty")
+	}
+	if from == to {
+		return fmt.Errorf("endpoint %s is already named %s", from, to)
+	}
+
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
+	}
+
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	localEndpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return err
+	}
+	var endpoint *brev_api.Endpoint
+	for i, v := range localEndpoints {
+		if v.Name == from {
+			endpoint = &localEndpoints[i]
+		}
+		if v.Name == to {
+			return fmt.Errorf("endpoint %s already exists", to)
+		}
+	}
+	if endpoint == nil {
+		return fmt.Errorf("endpoint %s doesn't exist", from)
+	}
+
+	module, err := brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: project.Id})
+	if err != nil {
+		return err
+	}
+	err = paths.Layout.CheckEndpoint(to, module.Name)
+	if err != nil {
+		return err
+	}
+
+	oldFile := paths.Layout.EndpointFile(from)
+	newFile := paths.Layout.EndpointFile(to)
+	exists, err := files.Exists(paths.Abs(newFile))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%s already exists, move it away before renaming", newFile)
+	}
+
+	// rename using the remote code so local changes are not pushed along the way
+	remote, err := getRemoteEndpoint(brevCtx, *endpoint)
+	if err != nil {
+		return err
+	}
+
+	renamed, err := brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
+		Id:      remote.Id,
+		Name:    to,
+		Methods: remote.Methods,
+		Code:    remote.Code,
+	})
+	if err != nil {
+		t.Errprint(err, "Failed to rename endpoint")
+		return err
+	}
+
+	err = files.Rename(paths.Abs(oldFile), paths.Abs(newFile))
+	if err != nil && !os.IsNotExist(err) {
+		// keep the remote name in step with the file
+		_, undoErr := brevCtx.Remote.SetEndpoint(*remote)
+		if undoErr != nil {
+			t.Errprint(undoErr, fmt.Sprintf("Failed to restore the remote name of %s", from))
+		}
+		t.Errprint(err, fmt.Sprintf("Failed to rename %s", oldFile))
+		return err
+	}
+
+	*endpoint = *renamed
+	err = brevCtx.Local.SetEndpoints(localEndpoints)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := brevCtx.Local.GetManifest()
+	if err != nil {
+		return err
+	}
+	if hash, ok := manifest[oldFile]; ok {
+		delete(manifest, oldFile)
+		manifest[newFile] = hash
+		err = brevCtx.Local.SetManifest(manifest)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.Vprint(t.Green("Endpoint ") + t.Yellow("%s", from) + t.Green(" renamed to ") + t.Yellow("%s", to) + " 🥞")
+	if remote.Uri != renamed.Uri {
+		t.Vprint(t.Yellow("\nWarning: the endpoint moved from %s%s to %s%s. Callers of the old URL need to be updated.",
+			project.Domain, remote.Uri, project.Domain, renamed.Uri))
+	}
+
+	return nil
+}
+
+func showEndpointMethods(name string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	_, endpoint, err := getLocalEndpoint(brevCtx, name)
+	if err != nil {
+		return err
+	}
+
+	remote, err := getRemoteEndpoint(brevCtx, *endpoint)
+	if err != nil {
+		return err
+	}
+
+	t.Vprint(fmt.Sprintf("%s %s", t.Green(name), formatMethods(remote.Methods)))
+	return nil
+}
+
+func setEndpointMethods(name string, methods []string, infer bool, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	endpoints, endpoint, err := getLocalEndpoint(brevCtx, name)
+	if err != nil {
+		return err
+	}
+
+	if infer {
+		paths, err := brevCtx.Global.GetProjectFiles()
+		if err != nil {
+			return err
+		}
+		code, err := files.ReadString(paths.Endpoint(name))
+		if err != nil {
+			return err
+		}
+		methods = brev_api.InferMethods(code)
+		if len(methods) == 0 {
+			return fmt.Errorf("%s defines none of get(), post(), put() and delete()", paths.Layout.EndpointFile(name))
+		}
+	} else {
+		methods, err = brev_api.ParseMethods(methods)
+		if err != nil {
+			return err
+		}
+		if len(methods) == 0 {
+			return errors.New("an endpoint needs at least one method")
+		}
+	}
+
+	remote, err := getRemoteEndpoint(brevCtx, *endpoint)
+	if err != nil {
+		return err
+	}
+	if brev_api.SameMethods(remote.Methods, methods) {
+		t.Vprint(t.Green("%s already serves %s 🥞", name, strings.Join(methods, ", ")))
+		return nil
+	}
+
+	// update using the remote code so local changes are not pushed along the way
+	updated, err := brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
+		Id:      remote.Id,
+		Name:    remote.Name,
+		Methods: methods,
+		Code:    remote.Code,
+	})
+	if err != nil {
+		t.Errprint(err, "Failed to update endpoint methods")
+		return err
+	}
+
+	endpoint.Methods = updated.Methods
+	err = brevCtx.Local.SetEndpoints(endpoints)
+	if err != nil {
+		return err
+	}
+
+	t.Vprint(t.Green("Endpoint ") + t.Yellow("%s", name) + t.Green(" now serves %s 🥞", strings.Join(updated.Methods, ", ")))
+	return nil
+}
+
+// getLocalEndpoint returns the endpoints of the current project along with a pointer
+// to the one with the given name
+func getLocalEndpoint(brevCtx *brev_ctx.BrevContext, name string) ([]brev_api.Endpoint, *brev_api.Endpoint, error) {
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, v := range endpoints {
+		if v.Name == name {
+			return endpoints, &endpoints[i], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("endpoint %s doesn't exist", name)
+}
+
+// getRemoteEndpoint returns the remote state of the given local endpoint
+func getRemoteEndpoint(brevCtx *brev_ctx.BrevContext, endpoint brev_api.Endpoint) (*brev_api.Endpoint, error) {
+	remoteEndpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ID: endpoint.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(remoteEndpoints) == 1 {
+		return &remoteEndpoints[0], nil
+	}
+	return nil, fmt.Errorf("endpoint %s was deleted remotely, run brev sync to update your project", endpoint.Name)
+}
+
+// formatMethods lists the methods an endpoint serves for display
+func formatMethods(methods []string) string {
+	if len(methods) == 0 {
+		return "[no methods declared]"
+	}
+	return "[" + strings.Join(methods, ", ") + "]"
+}
+
+func runEndpoint(name string, method string, arg []string, jsonBody string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
 	t.Vprint("\n")
 	bar := t.NewProgressBar("Running endpoint "+t.Yellow(name), func() {})
 	bar.AdvanceTo(40)
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -149,11 +445,11 @@ func runEndpoint(name string, method string, arg []string, jsonBody string, t *t
 	bar.Describe("Pushing endpoint")
 	bar.AdvanceTo(50)
 
-	path, err := getRootProjectDir(t)
+	paths, err := brevCtx.Global.GetProjectFiles()
 	if err != nil {
 		return err
 	}
-	endpoint.Code, err = files.ReadString(fmt.Sprintf("%s/%s.py", path, endpoint.Name))
+	endpoint.Code, err = files.ReadString(paths.Endpoint(endpoint.Name))
 	if err != nil {
 		return err
 	}
@@ -230,8 +526,75 @@ func runEndpoint(name string, method string, arg []string, jsonBody string, t *t
 	return nil
 }
 
-func listEndpoints(t *terminal.Terminal) error {
-	brevCtx, err := brev_ctx.New()
+func runEndpointLocally(name string, method string, arg []string, jsonBody string, python string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	// prepare query params
+	args := make(map[string]string)
+	for _, v := range arg {
+		if strings.Contains(v, "=") {
+			newArr := strings.SplitN(v, "=", 2)
+			args[newArr[0]] = newArr[1]
+		}
+	}
+
+	// prepare payload
+	var payload interface{}
+	if jsonBody != "" {
+		if err := json.Unmarshal([]byte(jsonBody), &payload); err != nil {
+			return fmt.Errorf(t.Red("failed to process JSON payload: %s", err))
+		}
+	}
+
+	runner := serve.NewRunner(python, paths)
+	defer runner.Close()
+
+	response, err := runner.Run(name, serve.Request{
+		Method: method,
+		Args:   args,
+		Body:   payload,
+	})
+	if err != nil {
+		t.Errprint(err, "Failed to run endpoint locally")
+		return err
+	}
+
+	// print output
+	t.Vprint(t.Yellow("\n%s %s (local)", strings.ToUpper(method), runner.EndpointPath(name)))
+	if 200 <= response.StatusCode && response.StatusCode < 300 {
+		t.Vprint(t.Green(" [%d]", response.StatusCode))
+	} else if response.StatusCode >= 400 {
+		t.Vprint(t.Red(" [%d]", response.StatusCode))
+	} else {
+		t.Vprint(t.Yellow(" [%d]", response.StatusCode))
+	}
+
+	t.Vprint("\n\nOutput:\n")
+	if response.Error != "" {
+		t.Vprint(t.Red("%s", response.Error))
+	} else {
+		jsonStr, err := (&requests.RESTResponse{Payload: response.Payload}).PayloadAsPrettyJSONString()
+		if err != nil {
+			jsonStr = string(response.Payload)
+		}
+		t.Vprint(jsonStr)
+	}
+	t.Vprint("\n\nLogs:\n")
+	t.Vprint(response.Stdout)
+
+	return nil
+}
+
+func listEndpoints(t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -253,41 +616,127 @@ func listEndpoints(t *terminal.Terminal) error {
 	// print
 	t.Vprint(fmt.Sprintf("\nEndpoints in project %s:\n", project.Name))
 	for _, endpoint := range endpoints {
-		t.Vprint(fmt.Sprintf("\t%s:", t.Green(endpoint.Name)))
+		t.Vprint(fmt.Sprintf("\t%s: %s", t.Green(endpoint.Name), formatMethods(endpoint.Methods)))
 		t.Vprint(fmt.Sprintf("\t%s%s\n", project.Domain, endpoint.Uri))
 	}
 
 	return nil
 }
 
-func logEndpoint(name string, t *terminal.Terminal) error {
-	t.Printf("Log ep file %s", name)
-	return nil
+func logEndpoint(name string, flags logs.Flags, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
+	}
+
+	flags.Uri, err = logs.ResolveEndpointURI(brevCtx, name)
+	if err != nil {
+		return err
+	}
+
+	options, err := flags.Options(time.Now())
+	if err != nil {
+		return err
+	}
+
+	t.Vprint(t.Yellow("\nLogs for endpoint %s:\n", name))
+	return logs.Show(t, brevCtx, *project, options)
 }
 
-func getRootProjectDir(t *terminal.Terminal) (string, error) {
+func endpointStats(name string, since string, until string, logType string, format string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %s: expected table or json", format)
+	}
 
-	brevCtx, err := brev_ctx.New()
+	now := time.Now()
+	var filter logs.Filter
+	var err error
+	filter.Since, err = logs.ParseTimeBound(since, now)
 	if err != nil {
-		return "", err
+		return err
+	}
+	filter.Until, err = logs.ParseTimeBound(until, now)
+	if err != nil {
+		return err
 	}
 
-	cwd, err := os.Getwd()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
-		t.Errprint(err, "Failed to determine working directory")
-		return "", err
+		return err
 	}
 
-	paths, err := brevCtx.Global.GetProjectPaths()
+	project, err := brevCtx.Local.GetProject()
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	var path string
-	for _, v := range paths {
-		if strings.Contains(cwd, v) {
-			path = v
+	endpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		filter.Uri, err = logs.ResolveEndpointURI(brevCtx, name)
+		if err != nil {
+			return err
 		}
 	}
-	return path, nil
+
+	projectLogs, err := brevCtx.Remote.GetLogs(*project, logType)
+	if err != nil {
+		return err
+	}
+	stats := logs.ComputeStats(filter.Apply(projectLogs), endpoints)
+
+	if format == "json" {
+		if stats == nil {
+			stats = []logs.EndpointStats{}
+		}
+		output, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		t.Vprint(string(output))
+		return nil
+	}
+
+	if len(stats) == 0 {
+		t.Vprint(t.Yellow("No requests in the selected window."))
+		return nil
+	}
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENDPOINT\tREQUESTS\tWALL P50\tWALL P90\tWALL P99\tCPU P50\tCPU P90\tCPU P99\tCPU TOTAL\t4XX\t5XX")
+	for _, stat := range stats {
+		label := stat.Name
+		if label == "" {
+			label = stat.Uri
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.1f%%\t%.1f%%\n",
+			label,
+			stat.Requests,
+			logs.FormatSeconds(stat.WallP50),
+			logs.FormatSeconds(stat.WallP90),
+			logs.FormatSeconds(stat.WallP99),
+			logs.FormatSeconds(stat.CpuP50),
+			logs.FormatSeconds(stat.CpuP90),
+			logs.FormatSeconds(stat.CpuP99),
+			logs.FormatSeconds(stat.CpuTotal),
+			stat.Rate4xx*100,
+			stat.Rate5xx*100,
+		)
+	}
+	w.Flush()
+
+	t.Vprint(t.Yellow("\nEndpoint stats for project %s:\n", project.Name))
+	t.Vprint(table.String())
+
+	return nil
 }
@@ -0,0 +1,87 @@
+package endpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_api/brevtest"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func TestRenameEndpoint(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	edited := "def get():\n    return 'edited'\n"
+	files.OverwriteString(filepath.Join(path, "hello.py"), edited)
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+	brevCtx.Local.SetManifest(brev_ctx.Manifest{"hello.py": brev_ctx.HashContents(fake.Endpoints[0].Code)})
+
+	if err := renameEndpoint("hello", "greeting", terminal.New(), fake.Factory()); err != nil {
+		t.Fatalf("renameEndpoint() returned error: %v", err)
+	}
+
+	remote := fake.Endpoints[0]
+	if remote.Name != "greeting" || remote.Uri != "/greeting" {
+		t.Errorf("remote endpoint = %s at %s, want greeting at /greeting", remote.Name, remote.Uri)
+	}
+	if remote.Code == edited {
+		t.Errorf("renaming pushed the unpushed local changes")
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "hello.py")); !os.IsNotExist(err) {
+		t.Errorf("hello.py still exists after rename")
+	}
+	contents, err := files.ReadString(filepath.Join(path, "greeting.py"))
+	if err != nil || contents != edited {
+		t.Errorf("greeting.py = %q, %v; want %q", contents, err, edited)
+	}
+
+	endpoints, _ := brevCtx.Local.GetEndpoints(nil)
+	if len(endpoints) != 2 || endpoints[0].Name != "greeting" || endpoints[0].Id != remote.Id || endpoints[1].Name != "world" {
+		t.Errorf("local endpoints = %+v, want greeting and world", endpoints)
+	}
+
+	manifest, _ := brevCtx.Local.GetManifest()
+	if _, ok := manifest["hello.py"]; ok || !manifest.Unchanged("greeting.py", remote.Code) {
+		t.Errorf("manifest = %v, want the base of hello.py moved to greeting.py", manifest)
+	}
+}
+
+func TestRenameEndpointRefusesExistingName(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	if err := renameEndpoint("hello", "world", terminal.New(), fake.Factory()); err == nil {
+		t.Errorf("renameEndpoint() to an existing endpoint returned no error")
+	}
+
+	files.OverwriteString(filepath.Join(path, "scratch.py"), "")
+	if err := renameEndpoint("hello", "scratch", terminal.New(), fake.Factory()); err == nil {
+		t.Errorf("renameEndpoint() onto an existing file returned no error")
+	}
+
+	if fake.Endpoints[0].Name != "hello" {
+		t.Errorf("remote endpoint was renamed to %s", fake.Endpoints[0].Name)
+	}
+}
+
+func TestEndpointRefusesModuleFile(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	brevtest.SetupProject(t, fake, "hello")
+
+	// in the default layout an endpoint named shared would live in shared.py
+	if err := addEndpoint("shared", nil, "", terminal.New(), fake.Factory()); err == nil {
+		t.Errorf("addEndpoint() accepted an endpoint in the module's file")
+	}
+	if err := renameEndpoint("hello", "shared", terminal.New(), fake.Factory()); err == nil {
+		t.Errorf("renameEndpoint() accepted an endpoint in the module's file")
+	}
+	if len(fake.Endpoints) != 1 || fake.Endpoints[0].Name != "hello" {
+		t.Errorf("remote endpoints = %+v, want only hello", fake.Endpoints)
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func NewCmdEnv(t *terminal.Terminal) *cobra.Command {
+func NewCmdEnv(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:         "env",
 		Annotations: map[string]string{"environment": ""},
@@ -30,18 +30,18 @@ func NewCmdEnv(t *terminal.Terminal) *cobra.Command {
 				return err
 			}
 
-			_, err = brev_api.CheckOutsideBrevErrorMessage(t)
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
 			return err
 		},
 	}
 
-	cmd.AddCommand(newCmdAdd(t))
-	cmd.AddCommand(newCmdRemove(t))
+	cmd.AddCommand(newCmdAdd(t, newClient))
+	cmd.AddCommand(newCmdRemove(t, newClient))
 
 	return cmd
 }
 
-func newCmdAdd(t *terminal.Terminal) *cobra.Command {
+func newCmdAdd(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	var name string
 	cmd := &cobra.Command{
 		Use:   "add",
@@ -53,7 +53,7 @@ func newCmdAdd(t *terminal.Terminal) *cobra.Command {
 		You will then be prompted for the value.
 		`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return addVariable(name, t)
+			return addVariable(name, t, newClient)
 		},
 	}
 	cmd.Flags().StringVarP(&name, "name", "n", "", "variable name")
@@ -62,7 +62,7 @@ func newCmdAdd(t *terminal.Terminal) *cobra.Command {
 	return cmd
 }
 
-func newCmdRemove(t *terminal.Terminal) *cobra.Command {
+func newCmdRemove(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	var name string
 
 	cmd := &cobra.Command{
@@ -70,14 +70,14 @@ func newCmdRemove(t *terminal.Terminal) *cobra.Command {
 		Short:   "Remove an environment variable",
 		Example: `  brev env remove --name XYZ`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return removeVariable(name, t)
+			return removeVariable(name, t, newClient)
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "variable name")
 	cmd.MarkFlagRequired("name")
 	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return getVariables(), cobra.ShellCompDirectiveNoSpace
+		return getVariables(newClient), cobra.ShellCompDirectiveNoSpace
 	})
 
 	return cmd
@@ -86,8 +86,8 @@ func newCmdRemove(t *terminal.Terminal) *cobra.Command {
 // For shell completions, let the command raise an error
 // if something fails here, just return nil
 // i.e. don't provide completion but let user continue
-func getVariables() []string {
-	brevCtx, err := brev_ctx.New()
+func getVariables(newClient brev_api.ClientFactory) []string {
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return nil
 	}
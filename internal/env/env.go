@@ -7,13 +7,12 @@ import (
 
 	"golang.org/x/term"
 
-	"github.com/brevdev/brev-go-cli/internal/auth"
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func addVariable(name string, t *terminal.Terminal) error {
+func addVariable(name string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
 
 	t.Vprintf("Enter value for %s: ", name)
 
@@ -25,7 +24,7 @@ func addVariable(name string, t *terminal.Terminal) error {
 
 	bar := t.NewProgressBar("Adding Variable "+t.Yellow(name), func() {})
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -45,11 +44,11 @@ func addVariable(name string, t *terminal.Terminal) error {
 	return nil
 }
 
-func removeVariable(name string, t *terminal.Terminal) error {
+func removeVariable(name string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
 
 	bar := t.NewProgressBar("Removing Variable "+t.Yellow(name), func() {})
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -63,21 +62,12 @@ func removeVariable(name string, t *terminal.Terminal) error {
 	projVars, err := brevCtx.Remote.GetVariables(*project, &brev_ctx.GetVariablesOptions{
 		Name: name,
 	})
-	if err != nil {
+	if err != nil || len(projVars) == 0 {
 		return errors.New(t.Red("There isn't a variable in your project named %s.", name))
 	}
 
-	token, err := auth.GetToken()
-	if err != nil {
-		t.Errprint(err, "Failed to retrieve auth token")
-		return err
-	}
-	brevAgent := brev_api.Agent{
-		Key: token,
-	}
-
 	// Remove variable by ID
-	_, err = brevAgent.RemoveVariable(projVars[0].Id)
+	err = brevCtx.Remote.DeleteVariable(projVars[0].Id)
 	if err != nil {
 		t.Errprint(err, "Couldn't remove the variable.")
 		return err
@@ -7,8 +7,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"os/user"
 	"path/filepath"
+	"sync"
 )
 
 const (
@@ -16,8 +16,38 @@ const (
 	activeProjectsFile = "active_projects.json"
 	projectsFile       = "projects.json"
 	endpointsFile      = "endpoints.json"
+	manifestFile       = "manifest.json"
 )
 
+// Recorder is told about the changes the write functions of this package would make.
+// While one is set, nothing is written, renamed or deleted.
+type Recorder interface {
+	RecordWrite(path string, size int)
+	RecordRename(from string, to string)
+	RecordDelete(path string)
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   Recorder
+)
+
+// SetRecorder sends every following change to the given Recorder instead of the
+// file system. A nil Recorder makes changes take place again.
+func SetRecorder(r Recorder) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+
+	recorder = r
+}
+
+func getRecorder() Recorder {
+	recorderMu.RLock()
+	defer recorderMu.RUnlock()
+
+	return recorder
+}
+
 func GetBrevDirectory() string {
 	return brevDirectory
 }
@@ -31,13 +61,16 @@ func GetProjectsFile() string {
 func GetEndpointsFile() string {
 	return endpointsFile
 }
+func GetManifestFile() string {
+	return manifestFile
+}
 
 func GetHomeDir() string {
-	usr, err := user.Current()
+	home, err := os.UserHomeDir()
 	if err != nil {
 		log.Fatal(err)
 	}
-	return usr.HomeDir
+	return home
 }
 
 func GetActiveProjectsPath() string {
@@ -125,6 +158,21 @@ func ReadString(filepath string) (string, error) {
 //   var foo myStruct
 //   OverwriteJSON("tmp/a/b/c.json", foo)
 func OverwriteJSON(filepath string, v interface{}) error {
+	if recorder := getRecorder(); recorder != nil {
+		dataBytes, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		recorder.RecordWrite(filepath, len(dataBytes))
+		return nil
+	}
+	return OverwriteJSONUnrecorded(filepath, v)
+}
+
+// OverwriteJSONUnrecorded is OverwriteJSON, but writes even while a Recorder is set.
+// It is meant for state which is no change of the command's, like a refreshed token
+// or a cache.
+func OverwriteJSONUnrecorded(filepath string, v interface{}) error {
 	f, err := touchFile(filepath)
 	if err != nil {
 		return nil
@@ -149,6 +197,15 @@ func OverwriteJSON(filepath string, v interface{}) error {
 // Usage
 //   OverwriteString("tmp/a/b/c.txt", "hi there")
 func OverwriteString(filepath string, data string) error {
+	if recorder := getRecorder(); recorder != nil {
+		recorder.RecordWrite(filepath, len(data))
+		return nil
+	}
+	return OverwriteStringUnrecorded(filepath, data)
+}
+
+// OverwriteStringUnrecorded is OverwriteString, but writes even while a Recorder is set
+func OverwriteStringUnrecorded(filepath string, data string) error {
 	f, err := touchFile(filepath)
 	if err != nil {
 		return nil
@@ -166,6 +223,11 @@ func OverwriteString(filepath string, data string) error {
 
 // Delete a single file altogether
 func DeleteFile(filepath string) error {
+	if recorder := getRecorder(); recorder != nil {
+		recorder.RecordDelete(filepath)
+		return nil
+	}
+
 	error := os.Remove(filepath)
 	if error != nil {
 		return nil
@@ -173,6 +235,30 @@ func DeleteFile(filepath string) error {
 	return error
 }
 
+// Rename moves a file, creating the directories leading up to its new path
+func Rename(from string, to string) error {
+	if recorder := getRecorder(); recorder != nil {
+		recorder.RecordRename(from, to)
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(to), 0755)
+	if err != nil {
+		return err
+	}
+	return os.Rename(from, to)
+}
+
+// Remove deletes a single file. Unlike DeleteFile, it reports failures.
+func Remove(filepath string) error {
+	if recorder := getRecorder(); recorder != nil {
+		recorder.RecordDelete(filepath)
+		return nil
+	}
+
+	return os.Remove(filepath)
+}
+
 // Create file (and full path) if it does not already exit
 func touchFile(path string) (*os.File, error) {
 	if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
@@ -7,16 +7,18 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/brevdev/brev-go-cli/internal/auth"
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
 	"github.com/brevdev/brev-go-cli/internal/brev_errors"
 	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
+	"github.com/brevdev/brev-go-cli/internal/parallel"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func NewCmdClone(t *terminal.Terminal) *cobra.Command {
+func NewCmdClone(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	var name string
+	var concurrency int
 
 	cmd := &cobra.Command{
 		Use:         "clone",
@@ -31,22 +33,19 @@ func NewCmdClone(t *terminal.Terminal) *cobra.Command {
 
 			bar.Describe(t.Yellow("Cloning project %s", name))
 
-			token, err := auth.GetToken()
+			brevCtx, err := brev_ctx.New(newClient)
 			if err != nil {
 				return err
 			}
 			bar.AdvanceTo(20)
-			brevAgent := brev_api.Agent{
-				Key: token,
-			}
-			projects, err := brevAgent.GetProjects()
+			projects, err := brevCtx.Remote.GetProjects(nil)
 			if err != nil {
-				return fmt.Errorf("failed to retrieve projects %v", err)
+				return fmt.Errorf("failed to retrieve projects: %w", err)
 			}
 			bar.AdvanceTo(30)
 
 			if name == "" {
-				err = initNewProject(t, bar)
+				err = initNewProject(t, bar, brevCtx)
 				if err != nil {
 					return err
 				}
@@ -55,9 +54,9 @@ func NewCmdClone(t *terminal.Terminal) *cobra.Command {
 			for _, v := range projects {
 
 				if v.Name == name {
-					err = initExistingProj(v, t, bar)
+					err = initExistingProj(v, t, bar, brevCtx, concurrency)
 					if err != nil {
-						return fmt.Errorf("failed to initialize project %v", err)
+						return fmt.Errorf("failed to initialize project: %w", err)
 					}
 					break // in case of error where multiple projects share name. We should prohibit this.
 				}
@@ -68,13 +67,14 @@ func NewCmdClone(t *terminal.Terminal) *cobra.Command {
 	cmd.Flags().StringVarP(&name, "name", "p", "", "Project Name")
 	cmd.MarkFlagRequired("name")
 	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return getProjectNames(), cobra.ShellCompDirectiveNoSpace
+		return getProjectNames(newClient), cobra.ShellCompDirectiveNoSpace
 	})
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "j", parallel.DefaultConcurrency, "number of files to write at once")
 
 	return cmd
 }
 
-func NewCmdInit(t *terminal.Terminal) *cobra.Command {
+func NewCmdInit(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:         "init",
@@ -89,7 +89,12 @@ func NewCmdInit(t *terminal.Terminal) *cobra.Command {
 
 			bar.Describe(t.Yellow("Initializing new project"))
 
-			err := initNewProject(t, bar)
+			brevCtx, err := brev_ctx.New(newClient)
+			if err != nil {
+				return err
+			}
+
+			err = initNewProject(t, bar, brevCtx)
 			if err != nil {
 				return err
 			}
@@ -101,14 +106,14 @@ func NewCmdInit(t *terminal.Terminal) *cobra.Command {
 	return cmd
 }
 
-func getProjectNames() []string {
+func getProjectNames(newClient brev_api.ClientFactory) []string {
 
 	// Get Projects
-	token, _ := auth.GetToken()
-	brevAgent := brev_api.Agent{
-		Key: token,
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return nil
 	}
-	rawProjects, _ := brevAgent.GetProjects()
+	rawProjects, _ := brevCtx.Remote.GetProjects(nil)
 	var projNames []string
 
 	// Filter list for just project names
@@ -120,7 +125,7 @@ func getProjectNames() []string {
 	return projNames
 }
 
-func initExistingProj(project brev_api.Project, t *terminal.Terminal, bar *terminal.ProgressBar) error {
+func initExistingProj(project brev_api.Project, t *terminal.Terminal, bar *terminal.ProgressBar, brevCtx *brev_ctx.BrevContext, concurrency int) error {
 
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -132,11 +137,6 @@ func initExistingProj(project brev_api.Project, t *terminal.Terminal, bar *termi
 	bar.Describe("creating local files")
 
 	// Get endpoints for project
-	brevCtx, err := brev_ctx.New()
-	if err != nil {
-		return err
-	}
-
 	endpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
 		ProjectID: project.Id,
 	})
@@ -178,13 +178,24 @@ func initExistingProj(project brev_api.Project, t *terminal.Terminal, bar *termi
 		}
 	}
 
+	paths, err := layout.New(path)
+	if err != nil {
+		return err
+	}
+
 	// Create endpoint files
-	for _, v := range endpoints {
-		err = files.OverwriteString(fmt.Sprintf("%s/%s.py", path, v.Name), v.Code)
+	err = parallel.Run(len(endpoints), concurrency, func(i int) error {
+		err := files.OverwriteString(paths.Endpoint(endpoints[i].Name), endpoints[i].Code)
 		if err != nil {
-			t.Errprint(err, "Failed to write code to local file")
-			return err
+			return fmt.Errorf("failed to write %s: %w", paths.Layout.EndpointFile(endpoints[i].Name), err)
 		}
+		return nil
+	}, func(finished int) {
+		bar.AdvanceTo(30 + 60*finished/len(endpoints))
+	})
+	if err != nil {
+		t.Errprint(err, "Failed to write code to local files")
+		return err
 	}
 
 	// Create shared code/module
@@ -195,10 +206,22 @@ func initExistingProj(project brev_api.Project, t *terminal.Terminal, bar *termi
 		return err
 	}
 
-	err = files.OverwriteString(fmt.Sprintf("%s/%s.py", path, module.Name), module.Source)
+	err = files.OverwriteString(paths.Module(module.Name), module.Source)
 	if err != nil {
 		return err
 	}
+
+	// record the cloned code as the base for later pushes and pulls
+	manifest := make(brev_ctx.Manifest)
+	for _, v := range endpoints {
+		manifest.Record(paths.Layout.EndpointFile(v.Name), v.Code)
+	}
+	manifest.Record(paths.Layout.ModuleFile(module.Name), module.Source)
+	err = files.OverwriteJSON(path+"/"+files.GetBrevDirectory()+"/"+files.GetManifestFile(), manifest)
+	if err != nil {
+		t.Errprint(err, "Failed to write manifest to local file")
+		return err
+	}
 	bar.Describe(t.Green("Brev project %s cloned.", project.Name))
 	bar.AdvanceTo(100)
 	completionString := t.Yellow("\ncd %s", project.Name) + t.Green(" and get started!") + t.Green("\n\nHappy Hacking 🥞")
@@ -208,7 +231,7 @@ func initExistingProj(project brev_api.Project, t *terminal.Terminal, bar *termi
 	return nil
 }
 
-func initNewProject(t *terminal.Terminal, bar *terminal.ProgressBar) error {
+func initNewProject(t *terminal.Terminal, bar *terminal.ProgressBar, brevCtx *brev_ctx.BrevContext) error {
 
 	// Get Project Name (parent folder-- behavior just like git init)
 	cwd, err := os.Getwd()
@@ -222,12 +245,10 @@ func initNewProject(t *terminal.Terminal, bar *terminal.ProgressBar) error {
 	projName := dirs[len(dirs)-1]
 
 	// Create new project
-	token, _ := auth.GetToken()
-	brevAgent := brev_api.Agent{
-		Key: token,
+	project, err := brevCtx.Remote.CreateProject(projName)
+	if err != nil {
+		return err
 	}
-	projectResponse, _ := brevAgent.CreateProject(projName)
-	project := projectResponse.Project
 
 	projectFilePath := cwd + "/" + files.GetBrevDirectory() + "/" + files.GetProjectsFile()
 	endpointsFilePath := cwd + "/" + files.GetBrevDirectory() + "/" + files.GetEndpointsFile()
@@ -249,7 +270,7 @@ func initNewProject(t *terminal.Terminal, bar *terminal.ProgressBar) error {
 	bar.AdvanceTo(40)
 
 	// Make project.json
-	err = files.OverwriteJSON(projectFilePath, project)
+	err = files.OverwriteJSON(projectFilePath, *project)
 	if err != nil {
 		t.Errprint(err, "Failed to write project to local file")
 		return err
@@ -290,10 +311,6 @@ func initNewProject(t *terminal.Terminal, bar *terminal.ProgressBar) error {
 	}
 
 	// Create shared code/module
-	brevCtx, err := brev_ctx.New()
-	if err != nil {
-		return err
-	}
 	proj, err := brevCtx.Local.GetProject()
 	if err != nil {
 		return err
@@ -304,7 +321,17 @@ func initNewProject(t *terminal.Terminal, bar *terminal.ProgressBar) error {
 	if err != nil {
 		return err
 	}
-	err = files.OverwriteString(fmt.Sprintf("%s/%s.py", cwd, module.Name), module.Source)
+	paths, err := layout.New(cwd)
+	if err != nil {
+		return err
+	}
+	err = files.OverwriteString(paths.Module(module.Name), module.Source)
+	if err != nil {
+		return err
+	}
+	manifest := make(brev_ctx.Manifest)
+	manifest.Record(paths.Layout.ModuleFile(module.Name), module.Source)
+	err = brevCtx.Local.SetManifest(manifest)
 	if err != nil {
 		return err
 	}
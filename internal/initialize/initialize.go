@@ -5,25 +5,24 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/brevdev/brev-go-cli/internal/auth"
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func initializeExistingProject(projectName string, t *terminal.Terminal) error {
-	project, endpoints, err := getRemoteProjectMatchingName(projectName)
+func initializeExistingProject(projectName string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
-		t.Errprintf(err, "Failed to retrieve project with name '%s'", projectName)
 		return err
 	}
 
-	// Set local
-	brevCtx, err := brev_ctx.New()
+	project, endpoints, err := getRemoteProjectMatchingName(projectName, brevCtx)
 	if err != nil {
+		t.Errprintf(err, "Failed to retrieve project with name '%s'", projectName)
 		return err
 	}
 
+	// Set local
 	if err = brevCtx.Local.SetProject(*project); err != nil {
 		return err
 	}
@@ -40,13 +39,9 @@ func initializeExistingProject(projectName string, t *terminal.Terminal) error {
 	return nil
 }
 
-func getRemoteProjectMatchingName(projectName string) (*brev_api.Project, *brev_api.Endpoints, error) {
+func getRemoteProjectMatchingName(projectName string, brevCtx *brev_ctx.BrevContext) (*brev_api.Project, *brev_api.Endpoints, error) {
 	// Get remote projects
-	token, _ := auth.GetToken()
-	brevAgent := brev_api.Agent{
-		Key: token,
-	}
-	remoteProjects, err := brevAgent.GetProjects()
+	remoteProjects, err := brevCtx.Remote.GetProjects(nil)
 	if err != nil {
 		return nil, nil, errors.New(fmt.Sprintf("Failed to retrieve remote projects: %s", err))
 	}
@@ -63,7 +58,7 @@ func getRemoteProjectMatchingName(projectName string) (*brev_api.Project, *brev_
 	}
 
 	// Get endpoints for project
-	remoteEndpoints, err := brevAgent.GetEndpoints()
+	remoteEndpoints, err := brevCtx.Remote.GetEndpoints(nil)
 	if err != nil {
 		return nil, nil, errors.New(fmt.Sprintf("Failed to retrieve remote endpoints: %s", err))
 	}
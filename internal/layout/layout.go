@@ -0,0 +1,170 @@
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/brevdev/brev-go-cli/internal/files"
+)
+
+const (
+	layoutFile  = "layout.json"
+	placeholder = "{name}"
+)
+
+// Layout decides where the code files of a project live. Each pattern is a path
+// relative to the project root, using forward slashes, whose file name holds a
+// {name} placeholder for the endpoint or module name.
+//
+// Example .brev/layout.json:
+//   {
+//       "endpoints": "endpoints/{name}.py",
+//       "modules": "shared/{name}.py"
+//   }
+type Layout struct {
+	Endpoints string `json:"endpoints"`
+	Modules   string `json:"modules"`
+}
+
+// Default keeps every file in the project root
+var Default = Layout{
+	Endpoints: "{name}.py",
+	Modules:   "{name}.py",
+}
+
+// Load reads the layout of the project at the given root from .brev/layout.json.
+// Projects without one, and patterns left out of it, use the Default layout.
+func Load(root string) (Layout, error) {
+	l := Default
+	layoutPath := filepath.Join(root, files.GetBrevDirectory(), layoutFile)
+	contents, err := ioutil.ReadFile(layoutPath)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, fmt.Errorf("failed to read %s: %w", layoutPath, err)
+	}
+
+	var configured Layout
+	err = json.Unmarshal(contents, &configured)
+	if err != nil {
+		return l, fmt.Errorf("failed to parse %s: %w", layoutPath, err)
+	}
+	if configured.Endpoints != "" {
+		l.Endpoints = configured.Endpoints
+	}
+	if configured.Modules != "" {
+		l.Modules = configured.Modules
+	}
+
+	err = l.Validate()
+	if err != nil {
+		return l, fmt.Errorf("invalid layout in %s: %w", layoutPath, err)
+	}
+	return l, nil
+}
+
+// Validate checks that the patterns name .py files inside the project. Whether an
+// endpoint and a module share a file depends on their names, see CheckEndpoint.
+func (l Layout) Validate() error {
+	for _, pattern := range []string{l.Endpoints, l.Modules} {
+		if path.IsAbs(pattern) || strings.Contains(pattern, "\\") {
+			return fmt.Errorf("%s must be a relative path using forward slashes", pattern)
+		}
+		if path.Clean(pattern) != pattern || strings.HasPrefix(pattern, "../") {
+			return fmt.Errorf("%s must be a clean path inside the project", pattern)
+		}
+		dir, file := path.Split(pattern)
+		if strings.Contains(dir, placeholder) || strings.Count(file, placeholder) != 1 {
+			return fmt.Errorf("%s must hold %s once, in its file name", pattern, placeholder)
+		}
+		if !strings.HasSuffix(file, ".py") {
+			return fmt.Errorf("%s must name a .py file", pattern)
+		}
+		if strings.HasPrefix(pattern, files.GetBrevDirectory()+"/") {
+			return fmt.Errorf("%s may not point into %s", pattern, files.GetBrevDirectory())
+		}
+	}
+	return nil
+}
+
+// EndpointFile returns the path of the named endpoint's file relative to the project root
+func (l Layout) EndpointFile(name string) string {
+	return strings.Replace(l.Endpoints, placeholder, name, 1)
+}
+
+// ModuleFile returns the path of the named module's file relative to the project root
+func (l Layout) ModuleFile(name string) string {
+	return strings.Replace(l.Modules, placeholder, name, 1)
+}
+
+// CheckEndpoint reports an error when the named endpoint's file is the file of one of
+// the given modules, as for an endpoint named shared in the Default layout
+func (l Layout) CheckEndpoint(name string, modules ...string) error {
+	for _, module := range modules {
+		if l.EndpointFile(name) == l.ModuleFile(module) {
+			return fmt.Errorf("endpoint %s and module %s would both live in %s", name, module, l.EndpointFile(name))
+		}
+	}
+	return nil
+}
+
+// Paths resolves the files of a project on disk. All code deciding where an
+// endpoint or module file lives goes through it.
+//
+// Example usage:
+//   paths, err := layout.New("/path/to/project")
+//   code, err := files.ReadString(paths.Endpoint("hello"))
+type Paths struct {
+	Root   string
+	Layout Layout
+}
+
+// New returns the Paths of the project at the given root, using its configured layout
+func New(root string) (*Paths, error) {
+	l, err := Load(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Paths{Root: root, Layout: l}, nil
+}
+
+// Abs turns a path relative to the project root into a path on disk
+func (p Paths) Abs(rel string) string {
+	return filepath.Join(p.Root, filepath.FromSlash(rel))
+}
+
+// Rel turns a path on disk into a slash-separated path relative to the project root.
+// It reports false for paths outside the project.
+func (p Paths) Rel(abs string) (string, bool) {
+	rel, err := filepath.Rel(p.Root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Endpoint returns the path on disk of the named endpoint's file
+func (p Paths) Endpoint(name string) string {
+	return p.Abs(p.Layout.EndpointFile(name))
+}
+
+// Module returns the path on disk of the named module's file
+func (p Paths) Module(name string) string {
+	return p.Abs(p.Layout.ModuleFile(name))
+}
+
+// EndpointDir returns the directory holding the endpoint files
+func (p Paths) EndpointDir() string {
+	return filepath.Dir(p.Endpoint(""))
+}
+
+// ModuleDir returns the directory holding the module files
+func (p Paths) ModuleDir() string {
+	return filepath.Dir(p.Module(""))
+}
@@ -0,0 +1,85 @@
+package layout
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+
+	l, err := Load(root)
+	if err != nil || l != Default {
+		t.Fatalf("Load() without layout.json = %+v, %v; want the default layout", l, err)
+	}
+
+	os.MkdirAll(filepath.Join(root, ".brev"), 0755)
+	ioutil.WriteFile(filepath.Join(root, ".brev", "layout.json"), []byte(`{"endpoints": "endpoints/{name}.py"}`), 0644)
+	l, err = Load(root)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if want := (Layout{Endpoints: "endpoints/{name}.py", Modules: "{name}.py"}); l != want {
+		t.Errorf("Load() = %+v, want %+v", l, want)
+	}
+
+	paths := Paths{Root: root, Layout: l}
+	if got, want := paths.Endpoint("hello"), filepath.Join(root, "endpoints", "hello.py"); got != want {
+		t.Errorf("Endpoint(hello) = %s, want %s", got, want)
+	}
+	if got, want := paths.Module("shared"), filepath.Join(root, "shared.py"); got != want {
+		t.Errorf("Module(shared) = %s, want %s", got, want)
+	}
+	if rel, ok := paths.Rel(paths.Endpoint("hello")); !ok || rel != "endpoints/hello.py" {
+		t.Errorf("Rel() = %s, %v; want endpoints/hello.py", rel, ok)
+	}
+	if _, ok := paths.Rel(filepath.Dir(root)); ok {
+		t.Error("Rel() accepted a path outside the project")
+	}
+
+	ioutil.WriteFile(filepath.Join(root, ".brev", "layout.json"), []byte(`{"endpoints": "../{name}.py"}`), 0644)
+	if _, err := Load(root); err == nil {
+		t.Error("Load() accepted a layout pointing outside the project")
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	if err := Default.CheckEndpoint("shared", "shared"); err == nil {
+		t.Error("CheckEndpoint() accepted an endpoint in the file of the shared module")
+	}
+	if err := Default.CheckEndpoint("hello", "shared"); err != nil {
+		t.Errorf("CheckEndpoint() returned error: %v", err)
+	}
+	l := Layout{Endpoints: "endpoints/{name}.py", Modules: "{name}.py"}
+	if err := l.CheckEndpoint("shared", "shared"); err != nil {
+		t.Errorf("CheckEndpoint() returned error for files in different directories: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{"{name}.py", "endpoints/{name}.py", "src/api/ep_{name}.py"}
+	for _, pattern := range valid {
+		if err := (Layout{Endpoints: pattern, Modules: "{name}.py"}).Validate(); err != nil {
+			t.Errorf("Validate(%s) returned error: %v", pattern, err)
+		}
+	}
+
+	invalid := []string{
+		"/abs/{name}.py",
+		"../{name}.py",
+		"./{name}.py",
+		"endpoints/main.py",
+		"{name}/main.py",
+		"{name}{name}.py",
+		"endpoints/{name}.txt",
+		".brev/{name}.py",
+		"endpoints\\{name}.py",
+	}
+	for _, pattern := range invalid {
+		if err := (Layout{Endpoints: pattern, Modules: "{name}.py"}).Validate(); err == nil {
+			t.Errorf("Validate(%s) returned no error", pattern)
+		}
+	}
+}
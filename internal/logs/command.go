@@ -0,0 +1,145 @@
+package logs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/cmdcontext"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// Flags holds the log filtering flags shared by `brev logs` and `brev endpoint log`
+type Flags struct {
+	Uri      string
+	Method   string
+	Status   string
+	Since    string
+	Until    string
+	LogType  string
+	Follow   bool
+	Interval time.Duration
+}
+
+// Register adds the log filtering flags to the given command
+func (f *Flags) Register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.Uri, "uri", "", "only show requests to this URI")
+	cmd.Flags().StringVarP(&f.Method, "method", "r", "", "only show requests with this http method")
+	cmd.RegisterFlagCompletionFunc("method", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"GET", "PUT", "POST", "DELETE"}, cobra.ShellCompDirectiveNoSpace
+	})
+	cmd.Flags().StringVarP(&f.Status, "status", "s", "", "only show status codes matching e.g. 404, 5xx or 400-499")
+	cmd.Flags().StringVar(&f.Since, "since", "", "only show requests after a duration ago (e.g. 1h) or a timestamp")
+	cmd.Flags().StringVar(&f.Until, "until", "", "only show requests before a duration ago (e.g. 10m) or a timestamp")
+	cmd.Flags().StringVar(&f.LogType, "type", defaultLogType, "type of logs to fetch")
+	cmd.Flags().BoolVarP(&f.Follow, "follow", "f", false, "keep polling and print new requests as they arrive")
+	cmd.Flags().DurationVar(&f.Interval, "interval", 2*time.Second, "polling interval when following")
+}
+
+// Options converts the flags into Show options, resolving relative times against now
+func (f *Flags) Options(now time.Time) (Options, error) {
+	minStatus, maxStatus, err := ParseStatusRange(f.Status)
+	if err != nil {
+		return Options{}, err
+	}
+	since, err := ParseTimeBound(f.Since, now)
+	if err != nil {
+		return Options{}, err
+	}
+	until, err := ParseTimeBound(f.Until, now)
+	if err != nil {
+		return Options{}, err
+	}
+
+	return Options{
+		Filter: Filter{
+			Uri:       f.Uri,
+			Method:    f.Method,
+			MinStatus: minStatus,
+			MaxStatus: maxStatus,
+			Since:     since,
+			Until:     until,
+		},
+		LogType:  f.LogType,
+		Follow:   f.Follow,
+		Interval: f.Interval,
+	}, nil
+}
+
+func NewCmdLogs(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var flags Flags
+	var name string
+
+	cmd := &cobra.Command{
+		Use:         "logs",
+		Annotations: map[string]string{"code": ""},
+		Short:       "View request logs for your project",
+		Long:        "View request logs for your project, optionally filtered and followed live.",
+		Example: `  brev logs
+  brev logs --endpoint MyEp --status 5xx --since 1h
+  brev logs --method POST --follow`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showLogs(t, newClient, name, flags)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "endpoint", "e", "", "only show requests to this endpoint")
+	cmd.RegisterFlagCompletionFunc("endpoint", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return brev_api.GetActiveEndpointNames(), cobra.ShellCompDirectiveNoSpace
+	})
+	flags.Register(cmd)
+
+	return cmd
+}
+
+func showLogs(t *terminal.Terminal, newClient brev_api.ClientFactory, endpointName string, flags Flags) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
+	}
+
+	if endpointName != "" {
+		uri, err := ResolveEndpointURI(brevCtx, endpointName)
+		if err != nil {
+			return err
+		}
+		flags.Uri = uri
+	}
+
+	options, err := flags.Options(time.Now())
+	if err != nil {
+		return err
+	}
+	return Show(t, brevCtx, *project, options)
+}
+
+// ResolveEndpointURI returns the URI of the local endpoint with the given name
+func ResolveEndpointURI(brevCtx *brev_ctx.BrevContext, name string) (string, error) {
+	endpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		Name: name,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf("no endpoint named %s in this project", name)
+	}
+	return endpoints[0].Uri, nil
+}
@@ -0,0 +1,244 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+const defaultLogType = "request"
+
+// Filter selects the project logs to display. Zero-valued fields match everything.
+type Filter struct {
+	Uri       string
+	Method    string
+	MinStatus int
+	MaxStatus int
+	Since     time.Time
+	Until     time.Time
+}
+
+// Options configures a call to Show
+type Options struct {
+	Filter   Filter
+	LogType  string
+	Follow   bool
+	Interval time.Duration
+}
+
+// Matches reports whether the given log passes every criterion of the filter
+func (f Filter) Matches(log brev_api.ProjectLog) bool {
+	if f.Uri != "" && stripQuery(log.Meta.Uri) != stripQuery(f.Uri) {
+		return false
+	}
+	if f.Method != "" && !strings.EqualFold(log.Meta.RequestMethod, f.Method) {
+		return false
+	}
+	if f.MinStatus > 0 && log.Meta.StatusCode < f.MinStatus {
+		return false
+	}
+	if f.MaxStatus > 0 && log.Meta.StatusCode > f.MaxStatus {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		timestamp, err := ParseTimestamp(log.Timestamp)
+		if err != nil {
+			return false
+		}
+		if !f.Since.IsZero() && timestamp.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && timestamp.After(f.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the logs matching the filter, ordered oldest first
+func (f Filter) Apply(logs []brev_api.ProjectLog) []brev_api.ProjectLog {
+	var filtered []brev_api.ProjectLog
+	for _, log := range logs {
+		if f.Matches(log) {
+			filtered = append(filtered, log)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Timestamp < filtered[j].Timestamp
+	})
+	return filtered
+}
+
+// ParseStatusRange parses a status code filter given either as a single code ("404"),
+// a class ("5xx") or an inclusive range ("400-499").
+func ParseStatusRange(value string) (int, int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	if len(value) == 3 && strings.HasSuffix(value, "xx") {
+		class, err := strconv.Atoi(value[:1])
+		if err != nil || class < 1 || class > 5 {
+			return 0, 0, fmt.Errorf("invalid status class %q", value)
+		}
+		return class * 100, class*100 + 99, nil
+	}
+
+	if parts := strings.SplitN(value, "-", 2); len(parts) == 2 {
+		min, minErr := strconv.Atoi(parts[0])
+		max, maxErr := strconv.Atoi(parts[1])
+		if minErr != nil || maxErr != nil || min > max {
+			return 0, 0, fmt.Errorf("invalid status range %q", value)
+		}
+		return min, max, nil
+	}
+
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid status code %q", value)
+	}
+	return code, code, nil
+}
+
+// ParseTimeBound parses a time window bound given either as a duration before now
+// ("90m") or as an absolute timestamp.
+func ParseTimeBound(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	return ParseTimestamp(value)
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999",
+	"2006-01-02 15:04:05.999999Z07:00",
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02",
+}
+
+// ParseTimestamp parses the timestamp formats produced by the Brev API
+func ParseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if timestamp, err := time.Parse(layout, value); err == nil {
+			return timestamp, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", value)
+}
+
+// Show prints the project's logs which match the options. In follow mode it keeps
+// polling for new logs, printing each request only once, until interrupted.
+func Show(t *terminal.Terminal, brevCtx *brev_ctx.BrevContext, project brev_api.Project, options Options) error {
+	logType := options.LogType
+	if logType == "" {
+		logType = defaultLogType
+	}
+
+	seen := make(map[string]bool)
+	printNew := func() (int, error) {
+		logs, err := brevCtx.Remote.GetLogs(project, logType)
+		if err != nil {
+			return 0, err
+		}
+		printed := 0
+		for _, log := range options.Filter.Apply(logs) {
+			key := logKey(log)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			t.Vprint(FormatLog(t, log))
+			printed += 1
+		}
+		return printed, nil
+	}
+
+	printed, err := printNew()
+	if err != nil {
+		return err
+	}
+	if !options.Follow {
+		if printed == 0 {
+			t.Vprint(t.Yellow("No logs matched."))
+		}
+		return nil
+	}
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case <-ticker.C:
+			if _, err := printNew(); err != nil {
+				t.Errprint(err, "Failed to poll logs, retrying")
+			}
+		}
+	}
+}
+
+// FormatLog renders a single request log on one line
+func FormatLog(t *terminal.Terminal, log brev_api.ProjectLog) string {
+	status := fmt.Sprintf("%d", log.Meta.StatusCode)
+	if log.Meta.StatusCode >= 500 {
+		status = t.Red(status)
+	} else if log.Meta.StatusCode >= 400 {
+		status = t.Yellow(status)
+	} else {
+		status = t.Green(status)
+	}
+
+	return fmt.Sprintf("%s %-6s %s %s wall %s cpu %s %s",
+		log.Timestamp,
+		log.Meta.RequestMethod,
+		log.Meta.Uri,
+		status,
+		FormatSeconds(log.Meta.WallTime),
+		FormatSeconds(log.Meta.CpuTime),
+		log.Meta.RequestId,
+	)
+}
+
+// FormatSeconds renders a duration reported by the API in seconds
+func FormatSeconds(seconds float64) string {
+	if seconds < 1 {
+		return fmt.Sprintf("%.1fms", seconds*1000)
+	}
+	return fmt.Sprintf("%.2fs", seconds)
+}
+
+func logKey(log brev_api.ProjectLog) string {
+	if log.Meta.RequestId != "" {
+		return log.Meta.RequestId
+	}
+	return log.Timestamp + " " + log.Meta.RequestMethod + " " + log.Meta.Uri
+}
+
+func stripQuery(uri string) string {
+	if i := strings.Index(uri, "?"); i >= 0 {
+		return uri[:i]
+	}
+	return uri
+}
@@ -0,0 +1,71 @@
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+)
+
+func TestParseStatusRange(t *testing.T) {
+	tests := []struct {
+		value   string
+		min     int
+		max     int
+		wantErr bool
+	}{
+		{value: "", min: 0, max: 0},
+		{value: "404", min: 404, max: 404},
+		{value: "5xx", min: 500, max: 599},
+		{value: "400-499", min: 400, max: 499},
+		{value: "499-400", wantErr: true},
+		{value: "9xx", wantErr: true},
+		{value: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		min, max, err := ParseStatusRange(tt.value)
+		if (err != nil) != tt.wantErr || min != tt.min || max != tt.max {
+			t.Errorf("ParseStatusRange(%q) = %d, %d, %v, want %d, %d, error %v", tt.value, min, max, err, tt.min, tt.max, tt.wantErr)
+		}
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	logs := []brev_api.ProjectLog{
+		{Timestamp: "2021-01-27T15:49:09.021461+00:00", Meta: brev_api.ProjectLogMeta{Uri: "/hello", RequestMethod: "GET", StatusCode: 200, RequestId: "a"}},
+		{Timestamp: "2021-01-27T15:48:00.000000+00:00", Meta: brev_api.ProjectLogMeta{Uri: "/hello?x=1", RequestMethod: "POST", StatusCode: 500, RequestId: "b"}},
+		{Timestamp: "2021-01-27T16:30:00.000000+00:00", Meta: brev_api.ProjectLogMeta{Uri: "/other", RequestMethod: "GET", StatusCode: 404, RequestId: "c"}},
+	}
+	now, _ := time.Parse(time.RFC3339, "2021-01-27T16:35:00Z")
+	since, _ := ParseTimeBound("10m", now)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "no filter sorts by time", filter: Filter{}, want: []string{"b", "a", "c"}},
+		{name: "uri ignores query", filter: Filter{Uri: "/hello"}, want: []string{"b", "a"}},
+		{name: "method", filter: Filter{Method: "get"}, want: []string{"a", "c"}},
+		{name: "status range", filter: Filter{MinStatus: 400, MaxStatus: 599}, want: []string{"b", "c"}},
+		{name: "since", filter: Filter{Since: since}, want: []string{"c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, log := range tt.filter.Apply(logs) {
+				got = append(got, log.Meta.RequestId)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Apply() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Apply() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package logs
+
+import (
+	"math"
+	"sort"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+)
+
+// EndpointStats summarizes the request logs of a single endpoint. Times are in seconds.
+type EndpointStats struct {
+	Name      string  `json:"name"`
+	Uri       string  `json:"uri"`
+	Requests  int     `json:"requests"`
+	WallP50   float64 `json:"wall_p50"`
+	WallP90   float64 `json:"wall_p90"`
+	WallP99   float64 `json:"wall_p99"`
+	CpuP50    float64 `json:"cpu_p50"`
+	CpuP90    float64 `json:"cpu_p90"`
+	CpuP99    float64 `json:"cpu_p99"`
+	CpuTotal  float64 `json:"cpu_total"`
+	Rate4xx   float64 `json:"rate_4xx"`
+	Rate5xx   float64 `json:"rate_5xx"`
+	Errors4xx int     `json:"errors_4xx"`
+	Errors5xx int     `json:"errors_5xx"`
+}
+
+// ComputeStats groups the logs by URI and summarizes each group. URIs belonging to
+// one of the given endpoints are reported under the endpoint's name. Results are
+// ordered by request count, busiest first.
+func ComputeStats(logs []brev_api.ProjectLog, endpoints []brev_api.Endpoint) []EndpointStats {
+	names := make(map[string]string)
+	for _, endpoint := range endpoints {
+		names[stripQuery(endpoint.Uri)] = endpoint.Name
+	}
+
+	type samples struct {
+		wall []float64
+		cpu  []float64
+		stat EndpointStats
+	}
+	groups := make(map[string]*samples)
+	for _, log := range logs {
+		uri := stripQuery(log.Meta.Uri)
+		group, ok := groups[uri]
+		if !ok {
+			group = &samples{stat: EndpointStats{Name: names[uri], Uri: uri}}
+			groups[uri] = group
+		}
+
+		group.wall = append(group.wall, log.Meta.WallTime)
+		group.cpu = append(group.cpu, log.Meta.CpuTime)
+		group.stat.Requests += 1
+		group.stat.CpuTotal += log.Meta.CpuTime
+		if log.Meta.StatusCode >= 500 {
+			group.stat.Errors5xx += 1
+		} else if log.Meta.StatusCode >= 400 {
+			group.stat.Errors4xx += 1
+		}
+	}
+
+	var stats []EndpointStats
+	for _, group := range groups {
+		sort.Float64s(group.wall)
+		sort.Float64s(group.cpu)
+
+		stat := group.stat
+		stat.WallP50 = Percentile(group.wall, 50)
+		stat.WallP90 = Percentile(group.wall, 90)
+		stat.WallP99 = Percentile(group.wall, 99)
+		stat.CpuP50 = Percentile(group.cpu, 50)
+		stat.CpuP90 = Percentile(group.cpu, 90)
+		stat.CpuP99 = Percentile(group.cpu, 99)
+		stat.Rate4xx = float64(stat.Errors4xx) / float64(stat.Requests)
+		stat.Rate5xx = float64(stat.Errors5xx) / float64(stat.Requests)
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Requests != stats[j].Requests {
+			return stats[i].Requests > stats[j].Requests
+		}
+		return stats[i].Uri < stats[j].Uri
+	})
+	return stats
+}
+
+// Percentile returns the p-th percentile of the sorted values using the nearest-rank method
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
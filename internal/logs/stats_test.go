@@ -0,0 +1,45 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+)
+
+func TestComputeStats(t *testing.T) {
+	var logs []brev_api.ProjectLog
+	for i := 1; i <= 10; i++ {
+		status := 200
+		if i == 9 {
+			status = 404
+		} else if i == 10 {
+			status = 503
+		}
+		logs = append(logs, brev_api.ProjectLog{Meta: brev_api.ProjectLogMeta{
+			Uri:        "/hello",
+			StatusCode: status,
+			WallTime:   float64(i) / 10,
+			CpuTime:    float64(i) / 100,
+		}})
+	}
+	logs = append(logs, brev_api.ProjectLog{Meta: brev_api.ProjectLogMeta{Uri: "/gone", StatusCode: 200, WallTime: 1}})
+
+	stats := ComputeStats(logs, []brev_api.Endpoint{{Name: "hello", Uri: "/hello"}})
+	if len(stats) != 2 {
+		t.Fatalf("ComputeStats() returned %d groups, want 2", len(stats))
+	}
+
+	hello := stats[0]
+	if hello.Name != "hello" || hello.Requests != 10 {
+		t.Errorf("first group = %s with %d requests, want hello with 10", hello.Name, hello.Requests)
+	}
+	if hello.WallP50 != 0.5 || hello.WallP90 != 0.9 || hello.WallP99 != 1 {
+		t.Errorf("wall percentiles = %v/%v/%v, want 0.5/0.9/1", hello.WallP50, hello.WallP90, hello.WallP99)
+	}
+	if hello.Rate4xx != 0.1 || hello.Rate5xx != 0.1 {
+		t.Errorf("error rates = %v/%v, want 0.1/0.1", hello.Rate4xx, hello.Rate5xx)
+	}
+	if stats[1].Name != "" || stats[1].Uri != "/gone" {
+		t.Errorf("second group = %q %q, want unnamed /gone", stats[1].Name, stats[1].Uri)
+	}
+}
@@ -1,11 +1,15 @@
 package package_project
 
 import (
+	"errors"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
 	"github.com/brevdev/brev-go-cli/internal/cmdcontext"
+	"github.com/brevdev/brev-go-cli/internal/parallel"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
@@ -13,8 +17,8 @@ func getTopPyPiPackages() []string {
 	return []string{"urllib3", "six", "boto3", "setuptools", "requests", "botocore", "idna", "certifi", "chardet", "pyyaml", "python-dateutil", "pip", "s3transfer", "wheel", "cffi", "rsa", "jmespath", "pyasn1", "numpy", "jinja"}
 }
 
-func GetPackages(t *terminal.Terminal) ([]brev_api.ProjectPackage, error) {
-	brevCtx, err := brev_ctx.New()
+func GetPackages(t *terminal.Terminal, newClient brev_api.ClientFactory) ([]brev_api.ProjectPackage, error) {
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return nil, err
 	}
@@ -27,8 +31,8 @@ func GetPackages(t *terminal.Terminal) ([]brev_api.ProjectPackage, error) {
 }
 
 // This is just used for autocomplete, so failures can just return no autocompletions
-func getCurrentPackages(t *terminal.Terminal) []string {
-	packages, err := GetPackages(t)
+func getCurrentPackages(t *terminal.Terminal, newClient brev_api.ClientFactory) []string {
+	packages, err := GetPackages(t, newClient)
 	if err != nil {
 		return []string{}
 	}
@@ -41,84 +45,227 @@ func getCurrentPackages(t *terminal.Terminal) []string {
 	return packageNames
 }
 
-func NewCmdPackage(t *terminal.Terminal) *cobra.Command {
+func NewCmdPackage(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:         "package",
 		Annotations: map[string]string{"environment": ""},
 		Short:       "Add or remove packages from your Brev project",
 		Long:        "Add or remove python packages from your project (like pip)",
-		Example: `  brev package add --name numpy
-  brev package remove --name numpy`,
+		Example: `  brev package add numpy==1.21.0
+  brev package remove --name numpy
+  brev package search numpy
+  brev package import -f requirements.txt
+  brev package export > requirements.txt`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			_, err = brev_api.CheckOutsideBrevErrorMessage(t)
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
 			return err
 		},
 	}
 
-	cmd.AddCommand(newCmdAdd(t))
-	cmd.AddCommand(newCmdRemove(t))
-	cmd.AddCommand(newCmdList(t))
+	cmd.AddCommand(newCmdAdd(t, newClient))
+	cmd.AddCommand(newCmdRemove(t, newClient))
+	cmd.AddCommand(newCmdList(t, newClient))
+	cmd.AddCommand(newCmdWait(t, newClient))
+	cmd.AddCommand(newCmdSearch(t))
+	cmd.AddCommand(newCmdInfo(t))
+	cmd.AddCommand(newCmdImport(t, newClient))
+	cmd.AddCommand(newCmdExport(t, newClient))
 
 	return cmd
 }
 
-func newCmdAdd(t *terminal.Terminal) *cobra.Command {
-	var name string
+func newCmdAdd(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var names []string
+	var wait bool
+	var timeout time.Duration
+	var concurrency int
 
 	cmd := &cobra.Command{
-		Use:     "add",
-		Short:   "Add a python package to your project",
-		Long:    "Installs a python package to your project (like pip).",
-		Example: `  brev package add --name numpy`,
+		Use:   "add [package...]",
+		Short: "Add python packages to your project",
+		Long: `Installs python packages to your project (like pip). Each package may carry
+a pip version specifier, such as numpy==1.21.0 or requests>=2.25,<3. Packages
+which are already installed are left alone.`,
+		Example: `  brev package add numpy
+  brev package add numpy==1.21.0 pandas
+  brev package add --name "requests>=2.25,<3"
+  brev package add numpy --wait`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completePackageNames(toComplete), cobra.ShellCompDirectiveNoSpace
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return addPackage(name, t)
+			names = append(names, args...)
+			if len(names) == 0 {
+				return errors.New("no package given")
+			}
+			return addPackages(names, wait, timeout, concurrency, t, newClient)
 		},
 	}
 
-	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the package")
-	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringArrayVarP(&names, "name", "n", nil, "name of a package, optionally with a version specifier")
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "wait until the packages are installed")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "give up waiting after this long")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "j", parallel.DefaultConcurrency, "number of packages to add at once")
 	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return getTopPyPiPackages(), cobra.ShellCompDirectiveNoSpace
+		return completePackageNames(toComplete), cobra.ShellCompDirectiveNoSpace
 	})
 
 	return cmd
 }
 
-func newCmdRemove(t *terminal.Terminal) *cobra.Command {
-	var name string
+func newCmdWait(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "wait [package...]",
+		Short: "Wait for packages to finish installing",
+		Long: `Waits until the given packages, or all packages still being installed, are
+installed or have failed. Exits with an error if any install failed or the
+timeout passed first.`,
+		Example: `  brev package wait
+  brev package wait numpy requests --timeout 5m`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return getCurrentPackages(t, newClient), cobra.ShellCompDirectiveNoSpace
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return waitPackages(args, timeout, t, newClient)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "give up waiting after this long")
+
+	return cmd
+}
+
+func newCmdSearch(t *terminal.Terminal) *cobra.Command {
+	var limit int
 
 	cmd := &cobra.Command{
-		Use:     "remove",
-		Short:   "Remove a python package from your project",
-		Long:    "Uninstalls a python package to your project (like pip).",
-		Example: `  brev package remove --name numpy`,
+		Use:   "search <term>",
+		Short: "Search PyPI for packages",
+		Long: `Searches the names of the packages on PyPI and shows the latest version and
+summary of the best matches. PyPI's answers are cached in ~/.brev/cache for a day.`,
+		Example: `  brev package search numpy
+  brev package search flask --limit 20`,
+		Args: cobra.ExactArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// PyPI can be searched outside of a project, so skip the project check of brev package
+			return cmdcontext.InvokeParentPersistentPreRun(cmd.Parent(), args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return removePackage(name, t)
+			return searchPackages(args[0], limit, t)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 10, "number of matches to show")
+
+	return cmd
+}
+
+func newCmdInfo(t *terminal.Terminal) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "info <package>",
+		Short: "Show a package's versions, summary and home page",
+		Long: `Shows what PyPI knows about a package: its versions, summary and home page.
+PyPI's answers are cached in ~/.brev/cache for a day.`,
+		Example: `  brev package info numpy`,
+		Args:    cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completePackageNames(toComplete), cobra.ShellCompDirectiveNoSpace
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// PyPI can be searched outside of a project, so skip the project check of brev package
+			return cmdcontext.InvokeParentPersistentPreRun(cmd.Parent(), args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return packageInfo(args[0], t)
+		},
+	}
+
+	return cmd
+}
+
+func newCmdImport(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Add the packages of a requirements.txt file",
+		Long: `Adds the packages of a requirements.txt file which are missing from your project.
+Installed packages whose version does not meet the file's specifier are reported,
+but left unchanged.`,
+		Example: `  brev package import -f requirements.txt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return importPackages(file, t, newClient)
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "requirements.txt", "requirements file to read")
+
+	return cmd
+}
+
+func newCmdExport(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print installed packages as a requirements.txt",
+		Long: `Prints the installed packages in requirements.txt form, pinned to their
+installed versions, so a local virtualenv can match the project.`,
+		Example: `  brev package export > requirements.txt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportPackages(t, newClient)
+		},
+	}
+
+	return cmd
+}
+
+func newCmdRemove(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var names []string
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "remove [package...]",
+		Short: "Remove python packages from your project",
+		Long: `Uninstalls python packages from your project (like pip). Nothing is removed
+unless every package is installed.`,
+		Example: `  brev package remove numpy
+  brev package remove numpy pandas
+  brev package remove --name numpy`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return getCurrentPackages(t, newClient), cobra.ShellCompDirectiveNoSpace
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names = append(names, args...)
+			if len(names) == 0 {
+				return errors.New("no package given")
+			}
+			return removePackages(names, concurrency, t, newClient)
 		},
 	}
 
-	cmd.Flags().StringVarP(&name, "name", "n", "", "name of the package")
-	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringArrayVarP(&names, "name", "n", nil, "name of a package")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "j", parallel.DefaultConcurrency, "number of packages to remove at once")
 	cmd.RegisterFlagCompletionFunc("name", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return getCurrentPackages(t), cobra.ShellCompDirectiveNoSpace
+		return getCurrentPackages(t, newClient), cobra.ShellCompDirectiveNoSpace
 	})
 
 	return cmd
 }
 
-func newCmdList(t *terminal.Terminal) *cobra.Command {
+func newCmdList(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "list",
 		Short:   "List installed packages",
 		Long:    "List installed packages.",
 		Example: `  brev package list`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listPackages(t)
+			return listPackages(t, newClient)
 		},
 	}
 
@@ -2,93 +2,215 @@ package package_project
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/brevdev/brev-go-cli/internal/auth"
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/parallel"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func addPackage(name string, t *terminal.Terminal) error {
-	bar := t.NewProgressBar("Adding package "+t.Yellow(name), func() {})
+// packageResult is the outcome of adding or removing a single package
+type packageResult struct {
+	name string
+	err  error
+	note string
+}
+
+func addPackages(requirements []string, wait bool, timeout time.Duration, concurrency int, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	// parse everything before changing anything
+	var parsed []Requirement
+	for _, v := range requirements {
+		r, err := ParseRequirement(v)
+		if err != nil {
+			return err
+		}
+		duplicate := false
+		for _, p := range parsed {
+			if p.Matches(r.Name) {
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			parsed = append(parsed, r)
+		}
+	}
 
-	brevCtx, err := brev_ctx.New()
+	bar := t.NewProgressBar(fmt.Sprintf("Adding %d packages", len(parsed)), func() {})
+
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
 
-	bar.AdvanceTo(40)
 	project, err := brevCtx.Local.GetProject()
 	if err != nil {
 		return err
 	}
 
-	_, err = brevCtx.Remote.SetPackage(*project, name)
+	packages, err := brevCtx.Remote.GetPackages(*project, nil)
 	if err != nil {
-		t.Errprintf(err, "\nFailed to add package %s", name)
 		return err
 	}
+	bar.AdvanceTo(20)
 
+	// packages already installed are left alone, which fails the add if the installed
+	// version is not the one asked for
+	var results []packageResult
+	var toAdd []Requirement
+	for _, r := range parsed {
+		installed := false
+		for _, v := range packages {
+			if !r.Matches(v.Name) {
+				continue
+			}
+			installed = true
+			if r.SatisfiedBy(v.Version) {
+				results = append(results, packageResult{name: r.String(), note: "already installed as " + packageLabel(v)})
+			} else {
+				err := fmt.Errorf("%s is installed, remove it first to change its version", packageLabel(v))
+				results = append(results, packageResult{name: r.String(), err: err})
+			}
+		}
+		if !installed {
+			toAdd = append(toAdd, r)
+		}
+	}
+
+	added := make([]packageResult, len(toAdd))
+	parallel.Run(len(toAdd), concurrency, func(i int) error {
+		_, err := brevCtx.Remote.SetPackage(*project, toAdd[i].Name, toAdd[i].Version())
+		added[i] = packageResult{name: toAdd[i].String(), err: err, note: "added"}
+		return err
+	}, func(finished int) {
+		bar.AdvanceTo(20 + 80*finished/len(toAdd))
+	})
 	bar.AdvanceTo(100)
-	finalStr := t.Green("Package ") + t.Yellow("%s", name) + t.Green(" added successfully 🥞")
-	t.Vprint(finalStr)
+	results = append(added, results...)
 
-	t.Vprint(t.Yellow(`
+	err = printResults(t, "add", results)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, r := range toAdd {
+		names = append(names, r.Name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	if wait {
+		if planner, ok := brevCtx.Remote.Planner(); ok {
+			// nothing was added during a dry run, so there is nothing to wait for
+			planner.PlanPoll("/_api/package", fmt.Sprintf("project_id=%s until %s installed", project.Id, strings.Join(names, ", ")))
+			return nil
+		}
+		t.Vprint("")
+		return waitForPackages(t, brevCtx, *project, names, DefaultWaitOptions(timeout))
+	}
 
-The package might take a moment to fully install.
-'brev package list' to see the status of all packages.
+	t.Vprint(t.Yellow(`
+Packages might take a moment to fully install.
+'brev package wait' to wait for them, or 'brev package list' to see the status of all packages.
 	`))
 
 	return nil
 }
 
-func removePackage(name string, t *terminal.Terminal) error {
-	bar := t.NewProgressBar("Removing package "+t.Yellow(name), func() {})
-
-	packages, err := GetPackages(t)
+func removePackages(names []string, concurrency int, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	var packageToRemove brev_api.ProjectPackage
-	for _, v := range packages {
-		if v.Name == name {
-			packageToRemove = v
-		}
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
 	}
 
-	token, err := auth.GetToken()
+	packages, err := brevCtx.Remote.GetPackages(*project, nil)
 	if err != nil {
-		t.Errprint(err, "Failed to retrieve auth token")
 		return err
 	}
-	brevAgent := brev_api.Agent{
-		Key: token,
+
+	// every package must exist before any is removed
+	var toRemove []brev_api.ProjectPackage
+	var missing []string
+	for _, name := range names {
+		found := false
+		for _, v := range packages {
+			if NormalizeName(v.Name) == NormalizeName(name) {
+				found = true
+				duplicate := false
+				for _, r := range toRemove {
+					duplicate = duplicate || r.Id == v.Id
+				}
+				if !duplicate {
+					toRemove = append(toRemove, v)
+				}
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return &brev_errors.PackageNotFoundError{Names: missing, Project: project.Name}
 	}
 
-	bar.AdvanceTo(60)
-	_, err = brevAgent.RemovePackage(packageToRemove.Id)
-	if err != nil {
-		t.Errprintf(err, "Failed to remove package %s", name)
+	bar := t.NewProgressBar(fmt.Sprintf("Removing %d packages", len(toRemove)), func() {})
+	results := make([]packageResult, len(toRemove))
+	parallel.Run(len(toRemove), concurrency, func(i int) error {
+		err := brevCtx.Remote.DeletePackage(toRemove[i].Id)
+		results[i] = packageResult{name: toRemove[i].Name, err: err, note: "removed"}
 		return err
-	}
-	finalStr := t.Green("\nPackage ") + t.Yellow("%s", name) + t.Green(" removed successfully 🥞")
+	}, func(finished int) {
+		bar.AdvanceTo(100 * finished / len(toRemove))
+	})
 
-	// bar.Describe(finalStr)
-	bar.AdvanceTo(100)
+	return printResults(t, "remove", results)
+}
 
-	t.Vprint(finalStr)
+// printResults prints a table of the outcome for each package and returns an
+// error if any of them failed
+func printResults(t *terminal.Terminal, action string, results []packageResult) error {
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tRESULT")
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\t%s\n", r.name, t.Red("failed: %s", r.err))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", r.name, t.Green("%s", r.note))
+		}
+	}
+	w.Flush()
+	t.Vprint("\n" + table.String())
 
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d packages", action, failed, len(results))
+	}
+	if len(results) > 0 {
+		t.Vprint(t.Green("Done 🥞"))
+	}
 	return nil
 }
 
-func listPackages(t *terminal.Terminal) error {
-	packages, err := GetPackages(t)
+func listPackages(t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	packages, err := GetPackages(t, newClient)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -101,7 +223,7 @@ func listPackages(t *terminal.Terminal) error {
 	t.Vprintf("Packages installed on project %s:\n", project.Name)
 
 	for _, v := range packages {
-		installStr := fmt.Sprintf("\t%s==%s ", v.Name, v.Version)
+		installStr := fmt.Sprintf("\t%s ", packageLabel(v))
 		if v.Status == "pending" {
 			t.Vprint(installStr + t.Yellow("%s", v.Status))
 		} else if v.Status == "installed" {
@@ -113,3 +235,157 @@ func listPackages(t *terminal.Terminal) error {
 
 	return nil
 }
+
+func importPackages(path string, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	requirements, err := ParseRequirements(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
+	}
+	packages, err := brevCtx.Remote.GetPackages(*project, nil)
+	if err != nil {
+		return err
+	}
+
+	var added, differing int
+	for _, r := range requirements {
+		var installed *brev_api.ProjectPackage
+		for i, v := range packages {
+			if r.Matches(v.Name) {
+				installed = &packages[i]
+				break
+			}
+		}
+
+		if installed == nil {
+			_, err = brevCtx.Remote.SetPackage(*project, r.Name, r.Version())
+			if err != nil {
+				t.Errprintf(err, "Failed to add package %s", r)
+				return err
+			}
+			t.Vprint(t.Green("  + %s", r))
+			added++
+			continue
+		}
+
+		// installed packages are never changed, only reported
+		if !r.SatisfiedBy(installed.Version) {
+			version := installed.Version
+			if version == "" {
+				version = "an unknown version"
+			}
+			t.Vprint(t.Yellow("  ~ %s wants %s, the project has %s", installed.Name, r.Specifier, version))
+			differing++
+		}
+	}
+
+	t.Vprint(t.Green("\n%d packages added from %s 🥞", added, path))
+	if differing > 0 {
+		t.Vprint(t.Yellow("%d installed packages differ from %s. Remove and add them again to change their version.", differing, path))
+	}
+	return nil
+}
+
+func exportPackages(t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	packages, err := GetPackages(t, newClient)
+	if err != nil {
+		return err
+	}
+
+	requirements, notes := formatRequirements(packages)
+	// the requirements go to stdout on their own so they can be redirected into a file
+	t.Vprintf("%s", requirements)
+	for _, note := range notes {
+		t.Eprint(t.Yellow("%s", note))
+	}
+	return nil
+}
+
+func searchPackages(term string, limit int, t *terminal.Terminal) error {
+	names, err := GetPyPINames()
+	if err != nil {
+		return err
+	}
+
+	matches := rankNames(names, term, limit)
+	if len(matches) == 0 {
+		t.Vprint(t.Yellow("No packages on PyPI match %s", term))
+		return nil
+	}
+
+	// a failed lookup only leaves the row without details
+	details := make([]*PyPIPackage, len(matches))
+	parallel.Run(len(matches), parallel.DefaultConcurrency, func(i int) error {
+		details[i], _ = GetPyPIPackage(matches[i])
+		return nil
+	}, nil)
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tVERSION\tSUMMARY")
+	for i, name := range matches {
+		var version, summary string
+		if details[i] != nil {
+			version = details[i].Version
+			summary = truncate(details[i].Summary, 72)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, version, summary)
+	}
+	w.Flush()
+	t.Vprint(table.String())
+
+	return nil
+}
+
+func packageInfo(name string, t *terminal.Terminal) error {
+	pkg, err := GetPyPIPackage(name)
+	if err != nil {
+		return err
+	}
+
+	t.Vprint(t.Green("%s", pkg.Name) + " " + pkg.Version)
+	if pkg.Summary != "" {
+		t.Vprint(pkg.Summary)
+	}
+	if pkg.HomePage != "" {
+		t.Vprint("Home page: " + pkg.HomePage)
+	}
+
+	versions := pkg.Versions
+	more := 0
+	if len(versions) > 10 {
+		more = len(versions) - 10
+		versions = versions[:10]
+	}
+	line := "Versions:  " + strings.Join(versions, ", ")
+	if more > 0 {
+		line += fmt.Sprintf(" and %d older", more)
+	}
+	t.Vprint(line)
+
+	t.Vprint(t.Yellow("\nAdd it with 'brev package add %s==%s'", pkg.Name, pkg.Version))
+	return nil
+}
+
+// truncate shortens the text to at most n runes
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
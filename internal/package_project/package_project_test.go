@@ -0,0 +1,122 @@
+package package_project
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_api/brevtest"
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/dryrun"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func packageNames(packages []brev_api.ProjectPackage) []string {
+	var names []string
+	for _, v := range packages {
+		names = append(names, v.Name+"@"+v.Version)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestAddPackages(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, _ := brevtest.SetupProject(t, fake)
+
+	fake.AddPackage(project.Id, "numpy", "1.20.0")
+
+	err := addPackages([]string{"numpy>=1.20", "pandas", "requests>=2.25,<3", "Pandas"}, false, 0, 2, terminal.New(), fake.Factory())
+	if err != nil {
+		t.Fatalf("addPackages() returned error: %v", err)
+	}
+	want := []string{"numpy@1.20.0", "pandas@", "requests@>=2.25,<3"}
+	if got := packageNames(fake.Packages); !reflect.DeepEqual(got, want) {
+		t.Errorf("packages = %v, want %v", got, want)
+	}
+
+	// an installed version other than the one asked for fails the add, but the
+	// other packages are still added
+	err = addPackages([]string{"numpy==1.21.0", "flask"}, false, 0, 2, terminal.New(), fake.Factory())
+	if err == nil {
+		t.Errorf("addPackages() accepted numpy==1.21.0 with numpy 1.20.0 installed")
+	}
+	want = []string{"flask@", "numpy@1.20.0", "pandas@", "requests@>=2.25,<3"}
+	if got := packageNames(fake.Packages); !reflect.DeepEqual(got, want) {
+		t.Errorf("packages = %v, want %v", got, want)
+	}
+}
+
+func TestAddPackagesDryRunWait(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	brevtest.SetupProject(t, fake)
+
+	plan := dryrun.NewPlan()
+	newClient := plan.Factory(fake.Factory())
+	plan.Start()
+	defer files.SetRecorder(nil)
+
+	// nothing is added, so waiting for the packages would never finish
+	if err := addPackages([]string{"numpy"}, true, time.Second, 2, terminal.New(), newClient); err != nil {
+		t.Fatalf("addPackages() returned error: %v", err)
+	}
+	if len(fake.Packages) != 0 {
+		t.Errorf("dry run added packages")
+	}
+	var actions []string
+	for _, step := range plan.Steps() {
+		actions = append(actions, step.Action+" "+step.Target)
+	}
+	want := []string{"POST /_api/package", "poll /_api/package"}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("planned steps = %v, want %v", actions, want)
+	}
+}
+
+func TestAddPackagesInvalidRequirement(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	brevtest.SetupProject(t, fake)
+
+	if err := addPackages([]string{"pandas", "numpy=1.0"}, false, 0, 2, terminal.New(), fake.Factory()); err == nil {
+		t.Fatalf("addPackages() returned no error for an invalid requirement")
+	}
+	if len(fake.Packages) != 0 {
+		t.Errorf("addPackages() added packages before rejecting the invalid one")
+	}
+}
+
+func TestRemovePackages(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, _ := brevtest.SetupProject(t, fake)
+
+	fake.AddPackage(project.Id, "numpy", "")
+	fake.AddPackage(project.Id, "pandas", "")
+	fake.AddPackage(project.Id, "requests", "")
+
+	if err := removePackages([]string{"numpy", "Pandas"}, 2, terminal.New(), fake.Factory()); err != nil {
+		t.Fatalf("removePackages() returned error: %v", err)
+	}
+	if got := packageNames(fake.Packages); !reflect.DeepEqual(got, []string{"requests@"}) {
+		t.Errorf("packages = %v, want only requests", got)
+	}
+}
+
+func TestRemoveMissingPackages(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, _ := brevtest.SetupProject(t, fake)
+
+	fake.AddPackage(project.Id, "numpy", "")
+
+	err := removePackages([]string{"numpy", "pandas", "scipy"}, 2, terminal.New(), fake.Factory())
+	var notFound *brev_errors.PackageNotFoundError
+	if !errors.As(err, &notFound) || !reflect.DeepEqual(notFound.Names, []string{"pandas", "scipy"}) {
+		t.Fatalf("removePackages() error = %v, want pandas and scipy not found", err)
+	}
+	if len(fake.Packages) != 1 {
+		t.Errorf("removePackages() removed packages although some did not exist")
+	}
+}
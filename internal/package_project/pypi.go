@@ -0,0 +1,242 @@
+package package_project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/config"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/requests"
+)
+
+const (
+	cacheDirectory = "cache"
+	pypiDirectory  = "pypi"
+	indexFile      = "index.txt"
+
+	// cacheMaxAge is how long PyPI answers are used before asking again
+	cacheMaxAge = 24 * time.Hour
+)
+
+// PyPIPackage is the metadata PyPI publishes about a package
+type PyPIPackage struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Summary  string   `json:"summary"`
+	HomePage string   `json:"home_page"`
+	Versions []string `json:"versions"`
+}
+
+// pypiProject is the response of PyPI's JSON API, see https://warehouse.pypa.io/api-reference/json.html
+type pypiProject struct {
+	Info struct {
+		Name        string            `json:"name"`
+		Version     string            `json:"version"`
+		Summary     string            `json:"summary"`
+		HomePage    string            `json:"home_page"`
+		ProjectURLs map[string]string `json:"project_urls"`
+	} `json:"info"`
+	Releases map[string][]json.RawMessage `json:"releases"`
+}
+
+// pypiIndex is the JSON form of PyPI's simple index, see PEP 691
+type pypiIndex struct {
+	Projects []struct {
+		Name string `json:"name"`
+	} `json:"projects"`
+}
+
+// getPyPICacheDir returns the directory caching PyPI answers
+func getPyPICacheDir() string {
+	return filepath.Join(files.GetHomeDir(), files.GetBrevDirectory(), cacheDirectory, pypiDirectory)
+}
+
+// readCache reports whether the file is cached and younger than cacheMaxAge
+func readCache(path string) (contents string, fresh bool, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, false
+	}
+	contents, err = files.ReadString(path)
+	if err != nil {
+		return "", false, false
+	}
+	return contents, time.Since(info.ModTime()) < cacheMaxAge, true
+}
+
+func getPyPI(path string, headers ...requests.Header) (*requests.RESTResponse, error) {
+	request := requests.RESTRequest{
+		Method:   "GET",
+		Endpoint: config.GetPyPIEndpoint() + path,
+		Headers:  headers,
+	}
+	return request.SubmitStrict()
+}
+
+// GetPyPIPackage returns the metadata of the named package, from the cache when it
+// was fetched recently. A stale cache entry is used if PyPI cannot be reached.
+func GetPyPIPackage(name string) (*PyPIPackage, error) {
+	cachePath := filepath.Join(getPyPICacheDir(), NormalizeName(name)+".json")
+	cached, fresh, ok := readCache(cachePath)
+	var pkg PyPIPackage
+	if ok && fresh && json.Unmarshal([]byte(cached), &pkg) == nil {
+		return &pkg, nil
+	}
+
+	response, err := getPyPI("/pypi/" + url.PathEscape(NormalizeName(name)) + "/json")
+	var responseErr *requests.RESTResponseError
+	if errors.As(err, &responseErr) && responseErr.ResponseStatusCode == 404 {
+		return nil, fmt.Errorf("no package %s on PyPI", name)
+	}
+	if err != nil {
+		if ok && json.Unmarshal([]byte(cached), &pkg) == nil {
+			return &pkg, nil
+		}
+		return nil, fmt.Errorf("failed to look up %s on PyPI: %w", name, err)
+	}
+
+	var project pypiProject
+	err = response.UnmarshalPayload(&project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize response payload: %s", err)
+	}
+
+	pkg = PyPIPackage{
+		Name:     project.Info.Name,
+		Version:  project.Info.Version,
+		Summary:  project.Info.Summary,
+		HomePage: project.Info.HomePage,
+	}
+	if pkg.HomePage == "" {
+		pkg.HomePage = project.Info.ProjectURLs["Homepage"]
+	}
+	for version, uploads := range project.Releases {
+		// releases without files were yanked or never finished uploading
+		if len(uploads) > 0 {
+			pkg.Versions = append(pkg.Versions, version)
+		}
+	}
+	sort.Slice(pkg.Versions, func(i, j int) bool { return compareVersions(pkg.Versions[i], pkg.Versions[j]) > 0 })
+
+	// the cache is no change of the command's, so it is refreshed during dry runs too
+	err = files.OverwriteJSONUnrecorded(cachePath, pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cache %s: %w", cachePath, err)
+	}
+	return &pkg, nil
+}
+
+// GetPyPINames returns the names of every package on PyPI, from the cache when it
+// was fetched recently. A stale cache is used if PyPI cannot be reached.
+func GetPyPINames() ([]string, error) {
+	cachePath := filepath.Join(getPyPICacheDir(), indexFile)
+	cached, fresh, ok := readCache(cachePath)
+	if ok && fresh {
+		return strings.Fields(cached), nil
+	}
+
+	response, err := getPyPI("/simple/", requests.Header{Key: "Accept", Value: "application/vnd.pypi.simple.v1+json"})
+	if err != nil {
+		if ok {
+			return strings.Fields(cached), nil
+		}
+		return nil, fmt.Errorf("failed to fetch the PyPI index: %w", err)
+	}
+
+	var index pypiIndex
+	err = response.UnmarshalPayload(&index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize response payload: %s", err)
+	}
+	var names []string
+	for _, v := range index.Projects {
+		names = append(names, v.Name)
+	}
+
+	err = files.OverwriteStringUnrecorded(cachePath, strings.Join(names, "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cache %s: %w", cachePath, err)
+	}
+	return names, nil
+}
+
+// getCachedPyPINames returns the cached PyPI package names, however old, without
+// going to the network. It returns nothing if the index was never fetched.
+func getCachedPyPINames() []string {
+	cached, _, ok := readCache(filepath.Join(getPyPICacheDir(), indexFile))
+	if !ok {
+		return nil
+	}
+	return strings.Fields(cached)
+}
+
+// rankNames returns up to limit names containing the term: exact matches first,
+// then names starting with it, then the rest, shorter names first within each
+func rankNames(names []string, term string, limit int) []string {
+	term = NormalizeName(term)
+	type match struct {
+		name string
+		rank int
+	}
+	var matches []match
+	for _, name := range names {
+		normalized := NormalizeName(name)
+		switch {
+		case normalized == term:
+			matches = append(matches, match{name, 0})
+		case strings.HasPrefix(normalized, term):
+			matches = append(matches, match{name, 1})
+		case strings.Contains(normalized, term):
+			matches = append(matches, match{name, 2})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		if len(matches[i].name) != len(matches[j].name) {
+			return len(matches[i].name) < len(matches[j].name)
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var ranked []string
+	for i := 0; i < len(matches) && (limit <= 0 || i < limit); i++ {
+		ranked = append(ranked, matches[i].name)
+	}
+	return ranked
+}
+
+// completePackageNames completes package names from the cached PyPI index. Until
+// the index was fetched once, and for an empty prefix, popular packages are offered.
+func completePackageNames(toComplete string) []string {
+	names := getCachedPyPINames()
+	if names == nil || toComplete == "" {
+		return getTopPyPiPackages()
+	}
+
+	var completions []string
+	prefix := strings.ToLower(toComplete)
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			completions = append(completions, name)
+		}
+	}
+	sort.Slice(completions, func(i, j int) bool {
+		if len(completions[i]) != len(completions[j]) {
+			return len(completions[i]) < len(completions[j])
+		}
+		return completions[i] < completions[j]
+	})
+	if len(completions) > 100 {
+		completions = completions[:100]
+	}
+	return completions
+}
@@ -0,0 +1,139 @@
+package package_project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/config"
+	"github.com/brevdev/brev-go-cli/internal/dryrun"
+	"github.com/brevdev/brev-go-cli/internal/files"
+)
+
+// setupPyPI points the PyPI endpoint at a stub server and the cache at a temporary
+// home directory. It returns the number of requests the stub has answered.
+func setupPyPI(t *testing.T) (*int, func()) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/pypi/numpy/json":
+			w.Write([]byte(`{
+				"info": {"name": "numpy", "version": "1.21.0", "summary": "Fundamental package for array computing", "home_page": "https://www.numpy.org"},
+				"releases": {"1.9.0": [{}], "1.21.0": [{}], "1.20.3": [{}], "2.0.0rc1": []}
+			}`))
+		case "/simple/":
+			if r.Header.Get("Accept") != "application/vnd.pypi.simple.v1+json" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Write([]byte(`{"projects": [{"name": "numpy-stl"}, {"name": "numpy"}, {"name": "pynumpy"}, {"name": "requests"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	home, err := ioutil.TempDir("", "brev-pypi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHome := os.Getenv("HOME")
+	oldEndpoint := config.PyPIEndpoint
+	os.Setenv("HOME", home)
+	config.PyPIEndpoint = server.URL
+
+	return &requests, func() {
+		config.PyPIEndpoint = oldEndpoint
+		os.Setenv("HOME", oldHome)
+		os.RemoveAll(home)
+		server.Close()
+	}
+}
+
+func TestGetPyPIPackage(t *testing.T) {
+	requests, cleanup := setupPyPI(t)
+	defer cleanup()
+
+	pkg, err := GetPyPIPackage("NumPy")
+	if err != nil {
+		t.Fatalf("GetPyPIPackage() returned error: %v", err)
+	}
+	want := &PyPIPackage{
+		Name:     "numpy",
+		Version:  "1.21.0",
+		Summary:  "Fundamental package for array computing",
+		HomePage: "https://www.numpy.org",
+		Versions: []string{"1.21.0", "1.20.3", "1.9.0"},
+	}
+	if !reflect.DeepEqual(pkg, want) {
+		t.Errorf("GetPyPIPackage() = %+v, want %+v", pkg, want)
+	}
+
+	// the second lookup is answered from the cache
+	cached, err := GetPyPIPackage("numpy")
+	if err != nil || !reflect.DeepEqual(cached, want) {
+		t.Errorf("cached GetPyPIPackage() = %+v, %v, want %+v", cached, err, want)
+	}
+	if *requests != 1 {
+		t.Errorf("PyPI was asked %d times, want once", *requests)
+	}
+}
+
+func TestGetPyPIPackageDryRun(t *testing.T) {
+	requests, cleanup := setupPyPI(t)
+	defer cleanup()
+
+	plan := dryrun.NewPlan()
+	plan.Start()
+	defer files.SetRecorder(nil)
+
+	// the cache is still written, and is no planned change
+	if _, err := GetPyPIPackage("numpy"); err != nil {
+		t.Fatalf("GetPyPIPackage() returned error: %v", err)
+	}
+	if _, err := GetPyPIPackage("numpy"); err != nil || *requests != 1 {
+		t.Errorf("cached GetPyPIPackage() asked PyPI %d times with error %v, want once", *requests, err)
+	}
+	if steps := plan.Steps(); len(steps) != 0 {
+		t.Errorf("planned steps = %+v, want none", steps)
+	}
+}
+
+func TestGetPyPIPackageNotFound(t *testing.T) {
+	_, cleanup := setupPyPI(t)
+	defer cleanup()
+
+	if _, err := GetPyPIPackage("no-such-package"); err == nil {
+		t.Errorf("GetPyPIPackage() returned no error for a missing package")
+	}
+}
+
+func TestGetPyPINames(t *testing.T) {
+	requests, cleanup := setupPyPI(t)
+	defer cleanup()
+
+	if names := completePackageNames("num"); !reflect.DeepEqual(names, getTopPyPiPackages()) {
+		t.Errorf("completePackageNames() before fetching the index = %v, want the popular packages", names)
+	}
+
+	names, err := GetPyPINames()
+	if err != nil {
+		t.Fatalf("GetPyPINames() returned error: %v", err)
+	}
+	if _, err := GetPyPINames(); err != nil || *requests != 1 {
+		t.Errorf("cached GetPyPINames() asked PyPI %d times with error %v, want once", *requests, err)
+	}
+
+	if got, want := rankNames(names, "NumPy", 0), []string{"numpy", "numpy-stl", "pynumpy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rankNames() = %v, want %v", got, want)
+	}
+	if got, want := rankNames(names, "numpy", 2), []string{"numpy", "numpy-stl"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rankNames() with a limit = %v, want %v", got, want)
+	}
+	if got, want := completePackageNames("Num"), []string{"numpy", "numpy-stl"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completePackageNames() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,219 @@
+package package_project
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+)
+
+var (
+	requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*(.*)$`)
+	clausePattern      = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*([A-Za-z0-9.*+!_-]+)$`)
+	separatorPattern   = regexp.MustCompile(`[-_.]+`)
+)
+
+// Requirement is a package along with an optional pip version specifier, as in
+// numpy==1.21.0 or requests>=2.25,<3
+type Requirement struct {
+	Name string
+	// Specifier holds the comma separated version clauses, e.g. ">=2.25,<3"
+	Specifier string
+}
+
+// ParseRequirement parses a requirement as written for pip. Extras and environment
+// markers are not supported, as the project has a single environment.
+func ParseRequirement(s string) (Requirement, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ";") {
+		return Requirement{}, fmt.Errorf("%s: environment markers are not supported", s)
+	}
+	match := requirementPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Requirement{}, fmt.Errorf("%q is not a valid requirement", s)
+	}
+	if match[2] != "" {
+		return Requirement{}, fmt.Errorf("%s: extras are not supported", s)
+	}
+
+	r := Requirement{Name: match[1]}
+	if match[3] == "" {
+		return r, nil
+	}
+	var clauses []string
+	for _, clause := range strings.Split(match[3], ",") {
+		parts := clausePattern.FindStringSubmatch(strings.TrimSpace(clause))
+		if parts == nil {
+			return Requirement{}, fmt.Errorf("%s: %q is not a valid version specifier", s, strings.TrimSpace(clause))
+		}
+		clauses = append(clauses, parts[1]+parts[2])
+	}
+	r.Specifier = strings.Join(clauses, ",")
+	return r, nil
+}
+
+// ParseRequirements reads a requirements.txt file. Blank lines and comments are
+// skipped, while pip options such as -r or -e are rejected.
+func ParseRequirements(reader io.Reader) ([]Requirement, error) {
+	var requirements []Requirement
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "-") {
+			return nil, fmt.Errorf("line %d: pip option %s is not supported", lineNumber, strings.Fields(line)[0])
+		}
+		r, err := ParseRequirement(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		requirements = append(requirements, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+func (r Requirement) String() string {
+	return r.Name + r.Specifier
+}
+
+// Version returns the version to install the package at: the pinned version for
+// ==, the whole specifier otherwise, and nothing for the latest release
+func (r Requirement) Version() string {
+	if strings.HasPrefix(r.Specifier, "==") && !strings.ContainsAny(r.Specifier, ",*") {
+		return strings.TrimPrefix(r.Specifier, "==")
+	}
+	return r.Specifier
+}
+
+// Matches reports whether the requirement names the given package, comparing
+// names the way pip does
+func (r Requirement) Matches(name string) bool {
+	return NormalizeName(r.Name) == NormalizeName(name)
+}
+
+// SatisfiedBy reports whether the given version meets every clause of the specifier
+func (r Requirement) SatisfiedBy(version string) bool {
+	if r.Specifier == "" {
+		return true
+	}
+	if version == "" {
+		return false
+	}
+	for _, clause := range strings.Split(r.Specifier, ",") {
+		parts := clausePattern.FindStringSubmatch(clause)
+		if parts == nil || !satisfies(version, parts[1], parts[2]) {
+			return false
+		}
+	}
+	return true
+}
+
+func satisfies(version string, operator string, want string) bool {
+	switch operator {
+	case "===":
+		return version == want
+	case "==", "!=":
+		equal := compareVersions(version, want) == 0
+		if strings.HasSuffix(want, ".*") {
+			prefix := strings.TrimSuffix(want, ".*")
+			equal = version == prefix || strings.HasPrefix(version, prefix+".")
+		}
+		return equal == (operator == "==")
+	case "<":
+		return compareVersions(version, want) < 0
+	case "<=":
+		return compareVersions(version, want) <= 0
+	case ">":
+		return compareVersions(version, want) > 0
+	case ">=":
+		return compareVersions(version, want) >= 0
+	case "~=":
+		// ~=1.4.2 means >=1.4.2,==1.4.*
+		segments := strings.Split(want, ".")
+		if len(segments) < 2 {
+			return false
+		}
+		prefix := strings.Join(segments[:len(segments)-1], ".")
+		return compareVersions(version, want) >= 0 && (version == prefix || strings.HasPrefix(version, prefix+"."))
+	}
+	return false
+}
+
+// compareVersions orders release versions segment by segment, numerically where
+// both segments are numbers. Missing segments count as zero, so 1.0 equals 1.0.0.
+func compareVersions(a string, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for len(as) < len(bs) {
+		as = append(as, "0")
+	}
+	for len(bs) < len(as) {
+		bs = append(bs, "0")
+	}
+	for i := range as {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		if aErr == nil && bErr == nil {
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// NormalizeName returns the name pip would compare, e.g. python-dateutil for Python_DateUtil
+func NormalizeName(name string) string {
+	return strings.ToLower(separatorPattern.ReplaceAllString(name, "-"))
+}
+
+// formatRequirements writes the installed packages in requirements.txt form, pinned to
+// their exact versions. Packages which are not installed are left out, and installed
+// packages without an exact version are written unpinned. It returns a note on each
+// package which was left out or unpinned.
+func formatRequirements(packages []brev_api.ProjectPackage) (string, []string) {
+	sorted := append([]brev_api.ProjectPackage(nil), packages...)
+	sort.Slice(sorted, func(i, j int) bool { return NormalizeName(sorted[i].Name) < NormalizeName(sorted[j].Name) })
+
+	var b strings.Builder
+	var notes []string
+	for _, v := range sorted {
+		switch {
+		case v.Status != statusInstalled:
+			status := v.Status
+			if status == "" {
+				status = statusPending
+			}
+			notes = append(notes, fmt.Sprintf("%s is %s and was left out", packageLabel(v), status))
+		case v.Version == "" || isSpecifier(v.Version):
+			notes = append(notes, fmt.Sprintf("%s has no exact version and is not pinned", packageLabel(v)))
+			b.WriteString(v.Name + "\n")
+		default:
+			b.WriteString(v.Name + "==" + v.Version + "\n")
+		}
+	}
+	return b.String(), notes
+}
@@ -0,0 +1,132 @@
+package package_project
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_api/brevtest"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		in          string
+		want        Requirement
+		wantVersion string
+		wantErr     bool
+	}{
+		{in: "numpy", want: Requirement{Name: "numpy"}, wantVersion: ""},
+		{in: "numpy==1.21.0", want: Requirement{Name: "numpy", Specifier: "==1.21.0"}, wantVersion: "1.21.0"},
+		{in: "requests >= 2.25, <3", want: Requirement{Name: "requests", Specifier: ">=2.25,<3"}, wantVersion: ">=2.25,<3"},
+		{in: "python-dateutil~=2.8", want: Requirement{Name: "python-dateutil", Specifier: "~=2.8"}, wantVersion: "~=2.8"},
+		{in: "django==3.2.*", want: Requirement{Name: "django", Specifier: "==3.2.*"}, wantVersion: "==3.2.*"},
+		{in: "requests[security]", wantErr: true},
+		{in: "numpy; python_version<'3.8'", wantErr: true},
+		{in: "numpy=1.0", wantErr: true},
+		{in: "==1.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRequirement(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRequirement(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got != tt.want || got.Version() != tt.wantVersion {
+			t.Errorf("ParseRequirement(%q) = %+v with version %q, want %+v with version %q", tt.in, got, got.Version(), tt.want, tt.wantVersion)
+		}
+	}
+}
+
+func TestParseRequirements(t *testing.T) {
+	got, err := ParseRequirements(strings.NewReader("# pinned\nnumpy==1.21.0\n\nrequests  # latest\n"))
+	if err != nil {
+		t.Fatalf("ParseRequirements() returned error: %v", err)
+	}
+	want := []Requirement{{Name: "numpy", Specifier: "==1.21.0"}, {Name: "requests"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRequirements() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseRequirements(strings.NewReader("numpy\n-r other.txt\n")); err == nil {
+		t.Errorf("ParseRequirements() accepted a pip option")
+	}
+}
+
+func TestSatisfiedBy(t *testing.T) {
+	tests := []struct {
+		specifier string
+		version   string
+		want      bool
+	}{
+		{"", "", true},
+		{"==1.21.0", "1.21.0", true},
+		{"==1.21", "1.21.0", true},
+		{"==1.21.0", "1.21.1", false},
+		{">=2.25,<3", "2.26.0", true},
+		{">=2.25,<3", "3.0", false},
+		{">=2.25", "2.9", false},
+		{"!=1.0", "1.0.0", false},
+		{"~=2.8", "2.9.1", true},
+		{"~=2.8", "3.0", false},
+		{"==3.2.*", "3.2.4", true},
+		{"==3.2.*", "3.20", false},
+		{"==1.0", "", false},
+	}
+	for _, tt := range tests {
+		r := Requirement{Name: "p", Specifier: tt.specifier}
+		if got := r.SatisfiedBy(tt.version); got != tt.want {
+			t.Errorf("%s satisfied by %q = %v, want %v", r, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRequirements(t *testing.T) {
+	got, notes := formatRequirements([]brev_api.ProjectPackage{
+		{Name: "requests", Version: "2.25.1", Status: "installed"},
+		{Name: "Flask", Status: "installed"},
+		{Name: "numpy", Version: "1.21.0", Status: "installed"},
+		{Name: "scipy", Version: ">=1.7", Status: "installed"},
+		{Name: "pandas", Version: "1.3.0", Status: "pending"},
+		{Name: "broken", Version: "0.1", Status: "failed"},
+	})
+	want := "Flask\nnumpy==1.21.0\nrequests==2.25.1\nscipy\n"
+	wantNotes := []string{
+		"broken==0.1 is failed and was left out",
+		"Flask has no exact version and is not pinned",
+		"pandas==1.3.0 is pending and was left out",
+		"scipy>=1.7 has no exact version and is not pinned",
+	}
+	if got != want || !reflect.DeepEqual(notes, wantNotes) {
+		t.Errorf("formatRequirements() = %q, %q, want %q, %q", got, notes, want, wantNotes)
+	}
+}
+
+func TestImportPackages(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, root := brevtest.SetupProject(t, fake)
+
+	fake.AddPackage(project.Id, "NumPy", "1.20.0")
+	fake.AddPackage(project.Id, "requests", "2.25.1")
+	requirements := filepath.Join(root, "requirements.txt")
+	files.OverwriteString(requirements, "numpy==1.21.0\nrequests>=2\nflask==2.0.1\n")
+
+	if err := importPackages(requirements, terminal.New(), fake.Factory()); err != nil {
+		t.Fatalf("importPackages() returned error: %v", err)
+	}
+
+	versions := make(map[string]string)
+	for _, v := range fake.Packages {
+		versions[v.Name] = v.Version
+	}
+	want := map[string]string{"NumPy": "1.20.0", "requests": "2.25.1", "flask": "2.0.1"}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("packages after import = %v, want %v", versions, want)
+	}
+}
@@ -0,0 +1,262 @@
+package package_project
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+const (
+	statusPending   = "pending"
+	statusInstalled = "installed"
+	statusFailed    = "failed"
+	statusError     = "error"
+
+	// statusRemoved marks packages which were removed while being waited for
+	statusRemoved = "removed"
+)
+
+// WaitOptions bound how long package installs are waited for and how often they
+// are polled
+type WaitOptions struct {
+	Timeout time.Duration
+
+	// BaseDelay and MaxDelay bound the exponential backoff between polls
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	sleep func(time.Duration)
+	now   func() time.Time
+}
+
+// DefaultWaitOptions polls every second at first, backing off to every 10 seconds
+func DefaultWaitOptions(timeout time.Duration) WaitOptions {
+	return WaitOptions{
+		Timeout:   timeout,
+		BaseDelay: time.Second,
+		MaxDelay:  10 * time.Second,
+	}
+}
+
+func (o WaitOptions) wait(d time.Duration) {
+	if o.sleep != nil {
+		o.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+func (o WaitOptions) currentTime() time.Time {
+	if o.now != nil {
+		return o.now()
+	}
+	return time.Now()
+}
+
+// failed reports whether the install of the package did not succeed
+func failed(p brev_api.ProjectPackage) bool {
+	switch p.Status {
+	case statusFailed, statusError, statusRemoved:
+		return true
+	}
+	return false
+}
+
+// installing reports whether the package is still being installed. Statuses which
+// are neither installed nor a known failure, such as an intermediate "installing",
+// are taken to be on the way.
+func installing(p brev_api.ProjectPackage) bool {
+	return p.Status != statusInstalled && !failed(p)
+}
+
+func waitPackages(names []string, timeout time.Duration, t *terminal.Terminal, newClient brev_api.ClientFactory) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
+	}
+	return waitForPackages(t, brevCtx, *project, names, DefaultWaitOptions(timeout))
+}
+
+// waitForPackages polls the project's packages until each named package is installed
+// or has failed, showing their status as it changes. Without names, it waits for
+// every package which is being installed. It returns an error if any install failed
+// or the timeout passed first.
+func waitForPackages(t *terminal.Terminal, brevCtx *brev_ctx.BrevContext, project brev_api.Project, names []string, options WaitOptions) error {
+	packages, err := brevCtx.Remote.GetPackages(project, nil)
+	if err != nil {
+		return err
+	}
+
+	var waitingFor []string
+	if len(names) == 0 {
+		for _, v := range packages {
+			if installing(v) {
+				waitingFor = append(waitingFor, v.Name)
+			}
+		}
+		if len(waitingFor) == 0 {
+			t.Vprint(t.Green("No packages are being installed 🥞"))
+			return nil
+		}
+	} else {
+		for _, name := range names {
+			found := false
+			for _, v := range packages {
+				if NormalizeName(v.Name) == NormalizeName(name) {
+					waitingFor = append(waitingFor, v.Name)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("package %s is not part of project %s", name, project.Name)
+			}
+		}
+	}
+	sort.Strings(waitingFor)
+
+	table := newStatusTable(t)
+	start := options.currentTime()
+	delay := options.BaseDelay
+	for {
+		states := make(map[string]brev_api.ProjectPackage)
+		for _, v := range packages {
+			states[v.Name] = v
+		}
+
+		var done, failedNames []string
+		var rows []brev_api.ProjectPackage
+		for _, name := range waitingFor {
+			state, ok := states[name]
+			if !ok {
+				// removed while being waited for
+				state = brev_api.ProjectPackage{Name: name, Status: statusRemoved}
+			}
+			rows = append(rows, state)
+			if installing(state) {
+				continue
+			}
+			done = append(done, name)
+			if failed(state) {
+				failedNames = append(failedNames, name)
+			}
+		}
+		elapsed := options.currentTime().Sub(start)
+		table.show(rows, elapsed)
+
+		if len(done) == len(waitingFor) {
+			if len(failedNames) > 0 {
+				return fmt.Errorf("%d of %d packages failed to install: %s", len(failedNames), len(waitingFor), strings.Join(failedNames, ", "))
+			}
+			t.Vprint(t.Green("\nAll packages installed 🥞"))
+			return nil
+		}
+		if options.Timeout > 0 && elapsed >= options.Timeout {
+			return fmt.Errorf("timed out after %s with %d packages still installing", options.Timeout, len(waitingFor)-len(done))
+		}
+
+		if options.Timeout > 0 && elapsed+delay > options.Timeout {
+			delay = options.Timeout - elapsed
+		}
+		options.wait(delay)
+		delay *= 2
+		if options.MaxDelay > 0 && delay > options.MaxDelay {
+			delay = options.MaxDelay
+		}
+
+		polled, err := brevCtx.Remote.GetPackages(project, nil)
+		if err != nil {
+			t.Errprint(err, "Failed to poll packages, retrying")
+			continue
+		}
+		packages = polled
+	}
+}
+
+// statusTable shows the status of packages being installed. On a terminal it is
+// redrawn in place, otherwise only changes are printed.
+type statusTable struct {
+	t          *terminal.Terminal
+	live       bool
+	linesDrawn int
+	lastStatus map[string]string
+}
+
+func newStatusTable(t *terminal.Terminal) *statusTable {
+	return &statusTable{
+		t:          t,
+		live:       term.IsTerminal(int(os.Stdout.Fd())),
+		lastStatus: make(map[string]string),
+	}
+}
+
+func (s *statusTable) show(rows []brev_api.ProjectPackage, elapsed time.Duration) {
+	width := 0
+	for _, v := range rows {
+		if len(packageLabel(v)) > width {
+			width = len(packageLabel(v))
+		}
+	}
+
+	if !s.live {
+		for _, v := range rows {
+			if s.lastStatus[v.Name] != v.Status {
+				s.lastStatus[v.Name] = v.Status
+				s.t.Vprint(fmt.Sprintf("%-*s  %s", width, packageLabel(v), s.colorStatus(v)))
+			}
+		}
+		return
+	}
+
+	if s.linesDrawn > 0 {
+		// move back up to redraw the table over itself
+		s.t.Vprintf("\033[%dA", s.linesDrawn)
+	}
+	s.t.Vprintf("\033[2KWaiting for %d packages (%s)\n", len(rows), elapsed.Round(time.Second))
+	for _, v := range rows {
+		s.t.Vprintf("\033[2K  %-*s  %s\n", width, packageLabel(v), s.colorStatus(v))
+	}
+	s.linesDrawn = len(rows) + 1
+}
+
+func (s *statusTable) colorStatus(p brev_api.ProjectPackage) string {
+	status := p.Status
+	if status == "" {
+		status = statusPending
+	}
+	if installing(p) {
+		return s.t.Yellow("%s", status)
+	}
+	if p.Status == statusInstalled {
+		return s.t.Green("%s", status)
+	}
+	return s.t.Red("%s", status)
+}
+
+func packageLabel(p brev_api.ProjectPackage) string {
+	if p.Version == "" {
+		return p.Name
+	}
+	if isSpecifier(p.Version) {
+		return p.Name + p.Version
+	}
+	return p.Name + "==" + p.Version
+}
+
+// isSpecifier reports whether the version is a version specifier rather than an
+// exact version
+func isSpecifier(version string) bool {
+	return version != "" && strings.ContainsAny(version[:1], "<>=!~")
+}
@@ -0,0 +1,126 @@
+package package_project
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// fakeClock returns wait options whose sleeps advance a fake clock and run the given
+// function, so tests can change package states between polls
+func fakeClock(timeout time.Duration, onSleep func(poll int)) (WaitOptions, *[]time.Duration) {
+	now := time.Unix(0, 0)
+	var delays []time.Duration
+	options := DefaultWaitOptions(timeout)
+	options.now = func() time.Time { return now }
+	options.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+		now = now.Add(d)
+		onSleep(len(delays))
+	}
+	return options, &delays
+}
+
+func TestWaitForPackages(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project := fake.AddProject("project")
+	fake.AddPackage(project.Id, "numpy", "1.21.0")
+	fake.AddPackage(project.Id, "requests", "")
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+
+	options, delays := fakeClock(time.Minute, func(poll int) {
+		if poll == 2 {
+			fake.Packages[0].Status = "installed"
+		}
+		if poll == 5 {
+			fake.Packages[1].Status = "installed"
+		}
+	})
+	if err := waitForPackages(terminal.New(), brevCtx, project, nil, options); err != nil {
+		t.Fatalf("waitForPackages() returned error: %v", err)
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	if len(*delays) != len(want) {
+		t.Fatalf("waited %v, want %v", *delays, want)
+	}
+	for i := range want {
+		if (*delays)[i] != want[i] {
+			t.Errorf("waited %v, want %v", *delays, want)
+			break
+		}
+	}
+}
+
+func TestWaitForPackagesFailure(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project := fake.AddProject("project")
+	fake.AddPackage(project.Id, "numpy", "")
+	fake.AddPackage(project.Id, "broken", "")
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+
+	options, _ := fakeClock(time.Minute, func(poll int) {
+		fake.Packages[0].Status = "installed"
+		fake.Packages[1].Status = "failed"
+	})
+	err := waitForPackages(terminal.New(), brevCtx, project, []string{"numpy", "broken"}, options)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("waitForPackages() error = %v, want the failed install of broken", err)
+	}
+}
+
+func TestWaitForPackagesUnknownStatus(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project := fake.AddProject("project")
+	fake.AddPackage(project.Id, "numpy", "")
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+
+	// statuses other than the known failures are waited on
+	options, delays := fakeClock(time.Minute, func(poll int) {
+		fake.Packages[0].Status = "installing"
+		if poll == 3 {
+			fake.Packages[0].Status = "installed"
+		}
+	})
+	if err := waitForPackages(terminal.New(), brevCtx, project, []string{"numpy"}, options); err != nil {
+		t.Fatalf("waitForPackages() returned error: %v", err)
+	}
+	if len(*delays) != 3 {
+		t.Errorf("polled %d times, want to wait until numpy was installed", len(*delays)+1)
+	}
+}
+
+func TestWaitForPackagesTimeout(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project := fake.AddProject("project")
+	fake.AddPackage(project.Id, "numpy", "")
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+
+	options, delays := fakeClock(5*time.Second, func(poll int) {})
+	err := waitForPackages(terminal.New(), brevCtx, project, []string{"NumPy"}, options)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("waitForPackages() error = %v, want a timeout", err)
+	}
+	var waited time.Duration
+	for _, d := range *delays {
+		waited += d
+	}
+	if waited != 5*time.Second {
+		t.Errorf("waited %v in total, want exactly the 5s timeout", waited)
+	}
+}
+
+func TestWaitForUnknownPackage(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project := fake.AddProject("project")
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+
+	options, _ := fakeClock(time.Minute, func(poll int) {})
+	if err := waitForPackages(terminal.New(), brevCtx, project, []string{"numpy"}, options); err == nil {
+		t.Errorf("waitForPackages() returned no error for a package outside the project")
+	}
+}
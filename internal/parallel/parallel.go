@@ -0,0 +1,83 @@
+package parallel
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is the number of workers used when none is configured
+const DefaultConcurrency = 8
+
+// Errors collects the errors of every failed task
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, "  "+err.Error())
+	}
+	return fmt.Sprintf("%d operations failed:\n%s", len(e), strings.Join(messages, "\n"))
+}
+
+// Run calls task for every index in [0, n) on a pool of at most concurrency workers.
+// Every task runs even if others fail; the errors are returned together as Errors.
+// onDone, if given, is called once per finished task with the number finished so far.
+// Calls to onDone never overlap.
+//
+// Example usage:
+//   err := parallel.Run(len(endpoints), 4, func(i int) error {
+//       return upload(endpoints[i])
+//   }, func(finished int) {
+//       bar.AdvanceTo(100 * finished / len(endpoints))
+//   })
+func Run(n int, concurrency int, task func(i int) error, onDone func(finished int)) error {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	indices := make(chan int)
+	errs := make([]error, n)
+	var mu sync.Mutex
+	finished := 0
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = task(i)
+
+				mu.Lock()
+				finished += 1
+				if onDone != nil {
+					onDone(finished)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	var failed Errors
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package parallel
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	var running, peak int32
+	var calls []int
+	err := Run(20, 3, func(i int) error {
+		now := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}, func(finished int) {
+		calls = append(calls, finished)
+	})
+	if err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", peak)
+	}
+	if len(calls) != 20 || calls[19] != 20 {
+		t.Errorf("onDone calls = %v, want 1 through 20", calls)
+	}
+}
+
+func TestRunCollectsErrors(t *testing.T) {
+	var ran int32
+	err := Run(10, 4, func(i int) error {
+		atomic.AddInt32(&ran, 1)
+		if i%3 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	}, nil)
+
+	if ran != 10 {
+		t.Errorf("ran %d tasks, want all 10", ran)
+	}
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Fatalf("Run() error = %v, want 4 collected errors", err)
+	}
+}
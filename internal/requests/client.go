@@ -0,0 +1,194 @@
+package requests
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client performs RESTRequests over an injectable http.RoundTripper, applying a
+// timeout to every attempt and retrying idempotent requests which fail transiently.
+type Client struct {
+	// Transport performs the underlying HTTP round trips. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// Timeout bounds each attempt of a request. Zero means no timeout.
+	Timeout time.Duration
+
+	// MaxRetries is the number of additional attempts made for idempotent requests
+	// (GET, PUT, DELETE) after a network error or a retryable status code.
+	MaxRetries int
+
+	// BaseDelay and MaxDelay bound the exponential backoff between attempts. A
+	// Retry-After header on a 429 or 503 response is honoured up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	sleep func(time.Duration)
+}
+
+// DefaultClient is the Client used by any RESTRequest which does not set its own.
+//
+// Tests may point the whole CLI at a stand-in server by replacing its Transport:
+//   requests.DefaultClient.Transport = myRoundTripper
+var DefaultClient = NewClient()
+
+// NewClient returns a Client with the CLI's default timeout and retry policy.
+func NewClient() *Client {
+	return &Client{
+		Timeout:    30 * time.Second,
+		MaxRetries: 3,
+		BaseDelay:  250 * time.Millisecond,
+		MaxDelay:   8 * time.Second,
+	}
+}
+
+// Do performs the given request, retrying it according to the client's policy.
+func (c *Client) Do(r *RESTRequest) (*RESTResponse, error) {
+	attempts := 1
+	if isIdempotent(r.Method) {
+		attempts += c.MaxRetries
+	}
+
+	var response *RESTResponse
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			c.wait(c.backoff(attempt, response))
+		}
+
+		response, err = c.attempt(r)
+		if err != nil {
+			continue
+		}
+		if !isRetryableStatus(response.StatusCode) {
+			return response, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) attempt(r *RESTRequest) (*RESTResponse, error) {
+	// the request is rebuilt on every attempt, as a payload reader cannot be replayed
+	req, err := r.BuildHTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := c.Timeout
+	if r.Timeout > 0 {
+		timeout = r.Timeout
+	}
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	httpClient := &http.Client{Transport: c.Transport}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	payloadBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers []Header
+	for key, values := range res.Header {
+		headers = append(headers, Header{
+			Key:   key,
+			Value: strings.Join(values, "\n"),
+		})
+	}
+	return &RESTResponse{
+		Headers:    headers,
+		StatusCode: res.StatusCode,
+		Payload:    payloadBytes,
+	}, nil
+}
+
+// backoff returns the delay before the given attempt, using exponential backoff
+// with jitter unless the previous response asked for a specific delay.
+func (c *Client) backoff(attempt int, previous *RESTResponse) time.Duration {
+	if previous != nil {
+		if delay, ok := retryAfter(previous); ok {
+			if c.MaxDelay > 0 && delay > c.MaxDelay {
+				return c.MaxDelay
+			}
+			return delay
+		}
+	}
+
+	delay := c.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (c.MaxDelay > 0 && delay > c.MaxDelay) {
+		delay = c.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// equal jitter: wait at least half of the computed delay
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+func (c *Client) wait(d time.Duration) {
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, which may be
+// given either in seconds or as an HTTP date.
+func retryAfter(response *RESTResponse) (time.Duration, bool) {
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := response.Header("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	return method == "GET" || method == "PUT" || method == "DELETE"
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
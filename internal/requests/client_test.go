@@ -0,0 +1,130 @@
+package requests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(sleeps *[]time.Duration) *Client {
+	client := NewClient()
+	client.sleep = func(d time.Duration) {
+		*sleeps = append(*sleeps, d)
+	}
+	return client
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	var sleeps []time.Duration
+	request := &RESTRequest{Method: "GET", Endpoint: server.URL, Client: newTestClient(&sleeps)}
+	response, err := request.SubmitStrict()
+	if err != nil {
+		t.Fatalf("SubmitStrict() returned error: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("got status %d after %d calls, want 200 after 3 calls", response.StatusCode, calls)
+	}
+	if len(sleeps) != 2 {
+		t.Errorf("got %d backoff sleeps, want 2", len(sleeps))
+	}
+}
+
+func TestClientDoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var sleeps []time.Duration
+	request := &RESTRequest{Method: "POST", Endpoint: server.URL, Client: newTestClient(&sleeps)}
+	response, err := request.Submit()
+	if err != nil {
+		t.Fatalf("Submit() returned error: %v", err)
+	}
+	if response.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("got status %d after %d calls, want 503 after 1 call", response.StatusCode, calls)
+	}
+}
+
+func TestClientHonoursRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var sleeps []time.Duration
+	request := &RESTRequest{Method: "DELETE", Endpoint: server.URL, Client: newTestClient(&sleeps)}
+	if _, err := request.SubmitStrict(); err != nil {
+		t.Fatalf("SubmitStrict() returned error: %v", err)
+	}
+	if len(sleeps) != 1 || sleeps[0] != 2*time.Second {
+		t.Errorf("got sleeps %v, want [2s]", sleeps)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var sleeps []time.Duration
+	client := newTestClient(&sleeps)
+	client.MaxRetries = 0
+	request := &RESTRequest{Method: "GET", Endpoint: server.URL, Client: client, Timeout: 20 * time.Millisecond}
+	if _, err := request.Submit(); err == nil {
+		t.Errorf("Submit() returned nil error, want timeout")
+	}
+}
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	target, _ := url.Parse(server.URL)
+	var sleeps []time.Duration
+	client := newTestClient(&sleeps)
+	client.Transport = rewriteTransport{target: target}
+
+	request := &RESTRequest{Method: "GET", Endpoint: "https://app.brev.dev/_api/_project", Client: client}
+	response, err := request.SubmitStrict()
+	if err != nil {
+		t.Fatalf("SubmitStrict() returned error: %v", err)
+	}
+	if got, _ := response.PayloadAsString(); got != "/_api/_project" {
+		t.Errorf("got path %q, want %q", got, "/_api/_project")
+	}
+}
@@ -6,9 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type RESTRequest struct {
@@ -18,6 +18,11 @@ type RESTRequest struct {
 	QueryParams []QueryParam
 	Headers     []Header
 	Payload     interface{}
+
+	// Client performs the request. If nil, DefaultClient is used.
+	Client *Client
+	// Timeout bounds each attempt of this request, overriding Client.Timeout when non-zero.
+	Timeout time.Duration
 }
 
 type RESTResponse struct {
@@ -39,6 +44,7 @@ type Header struct {
 type RESTResponseError struct {
 	RequestURI         string
 	ResponseStatusCode int
+	ResponsePayload    []byte
 }
 
 func (e *RESTResponseError) Error() string {
@@ -94,34 +100,11 @@ func (r *RESTRequest) BuildHTTPRequest() (*http.Request, error) {
 //   request = &RESTRequest{ ... }
 //   response, _ := request.Submit()
 func (r *RESTRequest) Submit() (*RESTResponse, error) {
-	req, err := r.BuildHTTPRequest()
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	payloadBytes, err := ioutil.ReadAll(res.Body)
-	defer res.Body.Close()
-	if err != nil {
-		return nil, err
+	client := r.Client
+	if client == nil {
+		client = DefaultClient
 	}
-
-	var headers []Header
-	for key, values := range res.Header {
-		headers = append(headers, Header{
-			Key:   key,
-			Value: strings.Join(values, "\n"),
-		})
-	}
-	return &RESTResponse{
-		Headers:    headers,
-		StatusCode: res.StatusCode,
-		Payload:    payloadBytes,
-	}, nil
+	return client.Do(r)
 }
 
 // SubmitStrict performs the HTTP request, returning a resultant RESTResponse if the response's status code is < 400.
@@ -137,6 +120,7 @@ func (r *RESTRequest) SubmitStrict() (*RESTResponse, error) {
 		return nil, &RESTResponseError{
 			RequestURI:         r.URI,
 			ResponseStatusCode: response.StatusCode,
+			ResponsePayload:    response.Payload,
 		}
 	}
 	return response, nil
@@ -151,6 +135,17 @@ func (r *RESTResponse) UnmarshalPayload(v interface{}) error {
 	return err
 }
 
+// Header returns the value of the response header with the given key, matched
+// case-insensitively, or an empty string if it is not present
+func (r *RESTResponse) Header(key string) string {
+	for _, header := range r.Headers {
+		if strings.EqualFold(header.Key, key) {
+			return header.Value
+		}
+	}
+	return ""
+}
+
 // PayloadAsString returns the response body as a string
 func (r *RESTResponse) PayloadAsString() (string, error) {
 	return string(r.Payload), nil
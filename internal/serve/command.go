@@ -0,0 +1,46 @@
+package serve
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/cmdcontext"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func NewCmdServe(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var host string
+	var port int
+	var python string
+
+	cmd := &cobra.Command{
+		Use:         "serve",
+		Annotations: map[string]string{"code": ""},
+		Short:       "Serve your endpoints locally",
+		Long: `Start a local HTTP server which runs your endpoints with a local Python interpreter.
+
+Requests are routed by method to the get(), post(), put() and delete() functions of
+each endpoint's file. Local stand-ins are provided for the variables and global_storage
+modules: variables are read from environment variables of the same name.`,
+		Example: `  brev serve
+  XYZ=secret brev serve --port 9000 --python python3.9`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve(t, host, port, python)
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "address to listen on")
+	cmd.Flags().IntVarP(&port, "port", "p", 8000, "port to listen on")
+	cmd.Flags().StringVar(&python, "python", "python3", "python interpreter used to run endpoints")
+
+	return cmd
+}
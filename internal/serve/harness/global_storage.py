@@ -0,0 +1,50 @@
+"""Local stand-in for the Brev global_storage module.
+
+storage_context behaves like a dict and is persisted to the file named by the
+BREV_LOCAL_STORAGE environment variable, so state survives between requests.
+"""
+import json
+import os
+
+
+class _StorageContext(dict):
+    def __init__(self, path):
+        super().__init__()
+        self._path = path
+        if path and os.path.exists(path):
+            with open(path) as f:
+                dict.update(self, json.load(f))
+
+    def _save(self):
+        if not self._path:
+            return
+        os.makedirs(os.path.dirname(self._path), exist_ok=True)
+        with open(self._path, "w") as f:
+            json.dump(dict(self), f, default=str)
+
+    def __setitem__(self, key, value):
+        super().__setitem__(key, value)
+        self._save()
+
+    def __delitem__(self, key):
+        super().__delitem__(key)
+        self._save()
+
+    def set(self, key, value):
+        self[key] = value
+
+    def update(self, *args, **kwargs):
+        super().update(*args, **kwargs)
+        self._save()
+
+    def pop(self, key, *default):
+        value = super().pop(key, *default)
+        self._save()
+        return value
+
+    def clear(self):
+        super().clear()
+        self._save()
+
+
+storage_context = _StorageContext(os.environ.get("BREV_LOCAL_STORAGE"))
@@ -0,0 +1,68 @@
+"""Runs a single request against a Brev endpoint file with a local interpreter.
+
+Usage: harness.py <endpoint file> <method>, with the request as JSON on stdin:
+    {"args": {"key": "value"}, "body": <any JSON value>}
+
+Writes one JSON object to stdout:
+    {"status": <int>, "response": <any>, "stdout": <str>, "error": <str>}
+"""
+import contextlib
+import importlib.util
+import inspect
+import io
+import json
+import os
+import sys
+import traceback
+
+
+def select_kwargs(handler, request):
+    data = dict(request.get("args") or {})
+    body = request.get("body")
+    if isinstance(body, dict):
+        data.update(body)
+
+    parameters = inspect.signature(handler).parameters.values()
+    if any(p.kind == inspect.Parameter.VAR_KEYWORD for p in parameters):
+        return data
+    names = {p.name for p in parameters}
+    if "body" in names and "body" not in data:
+        data["body"] = body
+    return {k: v for k, v in data.items() if k in names}
+
+
+def main():
+    endpoint_path, method = os.path.abspath(sys.argv[1]), sys.argv[2].lower()
+    request = json.load(sys.stdin)
+
+    # stand-in modules take precedence; the project root provides the shared module
+    sys.path.insert(0, os.path.dirname(endpoint_path))
+    sys.path.insert(0, os.path.dirname(os.path.abspath(__file__)))
+
+    result = {"status": 200, "response": None, "stdout": "", "error": ""}
+    stdout = io.StringIO()
+    try:
+        with contextlib.redirect_stdout(stdout):
+            spec = importlib.util.spec_from_file_location("endpoint", endpoint_path)
+            module = importlib.util.module_from_spec(spec)
+            spec.loader.exec_module(module)
+
+            handler = getattr(module, method, None)
+            if handler is None:
+                result["status"] = 405
+                result["error"] = "endpoint does not define %s()" % method
+            else:
+                response = handler(**select_kwargs(handler, request))
+                if isinstance(response, tuple) and len(response) == 2 and isinstance(response[1], int):
+                    response, result["status"] = response
+                result["response"] = response
+    except Exception:
+        result["status"] = 500
+        result["error"] = traceback.format_exc()
+    result["stdout"] = stdout.getvalue()
+
+    sys.__stdout__.write(json.dumps(result, default=str))
+
+
+if __name__ == "__main__":
+    main()
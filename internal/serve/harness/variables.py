@@ -0,0 +1,16 @@
+"""Local stand-in for the Brev variables module.
+
+Variable values never leave the Brev secrets manager, so locally they are read
+from environment variables of the same name:
+    XYZ=secret brev serve
+"""
+import os
+
+
+def __getattr__(name):
+    try:
+        return os.environ[name]
+    except KeyError:
+        raise AttributeError(
+            "variable %s is not set locally; export %s before running" % (name, name)
+        )
@@ -0,0 +1,186 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
+)
+
+//go:embed harness/*.py
+var harnessFiles embed.FS
+
+const localStorageFile = "local_storage.json"
+
+// Request is a single invocation of an endpoint function
+type Request struct {
+	Method string            `json:"-"`
+	Args   map[string]string `json:"args"`
+	Body   interface{}       `json:"body"`
+}
+
+// Response is the outcome of running an endpoint function locally
+type Response struct {
+	StatusCode int             `json:"status"`
+	Payload    json.RawMessage `json:"response"`
+	Stdout     string          `json:"stdout"`
+	Error      string          `json:"error"`
+}
+
+// Runner executes endpoint files of a project against a local Python interpreter.
+// Each request runs in a fresh interpreter, so edits are picked up immediately.
+//
+// Example usage:
+//   paths, _ := layout.New("/path/to/project")
+//   runner := serve.NewRunner("python3", paths)
+//   defer runner.Close()
+//   response, err := runner.Run("hello", serve.Request{Method: "GET"})
+type Runner struct {
+	Python  string
+	Paths   *layout.Paths
+	Timeout time.Duration
+
+	mu         sync.Mutex
+	harnessDir string
+}
+
+// NewRunner returns a Runner for the project whose files are resolved by the given paths
+func NewRunner(python string, paths *layout.Paths) *Runner {
+	if python == "" {
+		python = "python3"
+	}
+	return &Runner{
+		Python:  python,
+		Paths:   paths,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// Run invokes the function matching the request method in the named endpoint's file
+func (r *Runner) Run(endpointName string, request Request) (*Response, error) {
+	endpointPath := r.EndpointPath(endpointName)
+	exists, err := files.Exists(endpointPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("endpoint file %s not found", endpointPath)
+	}
+
+	harnessDir, err := r.writeHarness()
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	method := strings.ToLower(request.Method)
+	if method == "" {
+		method = "get"
+	}
+	cmd := exec.CommandContext(ctx, r.Python, filepath.Join(harnessDir, "harness.py"), endpointPath, method)
+	cmd.Dir = r.Paths.Root
+	cmd.Env = append(os.Environ(),
+		"BREV_LOCAL_STORAGE="+filepath.Join(r.Paths.Root, files.GetBrevDirectory(), localStorageFile),
+		// endpoints import the shared module, which may live in another directory
+		"PYTHONPATH="+pythonPath(r.Paths.ModuleDir()),
+		"PYTHONDONTWRITEBYTECODE=1",
+	)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("endpoint %s timed out after %s", endpointName, r.Timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s with %s: %w\n%s", endpointPath, r.Python, err, stderr.String())
+	}
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("failed to decode endpoint output: %w\n%s", err, stderr.String())
+	}
+	return &response, nil
+}
+
+// EndpointPath returns the path of the file holding the named endpoint's code
+func (r *Runner) EndpointPath(endpointName string) string {
+	return r.Paths.Endpoint(endpointName)
+}
+
+// pythonPath prepends the given directory to the PYTHONPATH of the environment
+func pythonPath(dir string) string {
+	if existing := os.Getenv("PYTHONPATH"); existing != "" {
+		return dir + string(os.PathListSeparator) + existing
+	}
+	return dir
+}
+
+// Close removes the harness files written by the runner
+func (r *Runner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.harnessDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(r.harnessDir)
+	r.harnessDir = ""
+	return err
+}
+
+// writeHarness writes the harness and stand-in modules to a temporary directory
+// the first time they are needed
+func (r *Runner) writeHarness() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.harnessDir != "" {
+		return r.harnessDir, nil
+	}
+
+	dir, err := ioutil.TempDir("", "brev-serve")
+	if err != nil {
+		return "", err
+	}
+	entries, err := harnessFiles.ReadDir("harness")
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		content, err := harnessFiles.ReadFile("harness/" + entry.Name())
+		if err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, entry.Name()), content, 0644); err != nil {
+			return "", err
+		}
+	}
+
+	r.harnessDir = dir
+	return dir, nil
+}
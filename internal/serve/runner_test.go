@@ -0,0 +1,148 @@
+package serve
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/layout"
+)
+
+const testEndpoint = `import variables
+from global_storage import storage_context
+
+def get(name="world"):
+    print("hello from", name)
+    return {"hello": name, "key": variables.API_KEY}
+
+def post(body):
+    storage_context["count"] = storage_context.get("count", 0) + 1
+    return {"echo": body, "count": storage_context["count"]}, 201
+
+def put():
+    raise ValueError("boom")
+`
+
+func newTestRunner(t *testing.T) *Runner {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+
+	root := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(root, "hello.py"), []byte(testEndpoint), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the runner passes its environment on to the endpoint
+	oldKey, hadKey := os.LookupEnv("API_KEY")
+	os.Setenv("API_KEY", "secret")
+	t.Cleanup(func() {
+		if hadKey {
+			os.Setenv("API_KEY", oldKey)
+		} else {
+			os.Unsetenv("API_KEY")
+		}
+	})
+
+	runner := NewRunner(python, &layout.Paths{Root: root, Layout: layout.Default})
+	t.Cleanup(func() { runner.Close() })
+	return runner
+}
+
+func TestRunnerLayout(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+
+	root := t.TempDir()
+	paths := &layout.Paths{Root: root, Layout: layout.Layout{Endpoints: "api/{name}.py", Modules: "lib/{name}.py"}}
+	os.MkdirAll(filepath.Dir(paths.Endpoint("hello")), 0755)
+	os.MkdirAll(filepath.Dir(paths.Module("shared")), 0755)
+	ioutil.WriteFile(paths.Endpoint("hello"), []byte("import shared\n\ndef get():\n    return {\"x\": shared.X}\n"), 0644)
+	ioutil.WriteFile(paths.Module("shared"), []byte("X = 42\n"), 0644)
+
+	runner := NewRunner(python, paths)
+	defer runner.Close()
+
+	response, err := runner.Run("hello", Request{Method: "GET"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != 200 || string(response.Payload) != `{"x": 42}` {
+		t.Errorf("unexpected response %d %s: %s", response.StatusCode, response.Payload, response.Error)
+	}
+}
+
+func TestRunnerGet(t *testing.T) {
+	runner := newTestRunner(t)
+
+	response, err := runner.Run("hello", Request{Method: "GET", Args: map[string]string{"name": "brev"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d: %s", response.StatusCode, response.Error)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(response.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["hello"] != "brev" || payload["key"] != "secret" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+	if strings.TrimSpace(response.Stdout) != "hello from brev" {
+		t.Errorf("unexpected stdout %q", response.Stdout)
+	}
+}
+
+func TestRunnerPostPersistsStorage(t *testing.T) {
+	runner := newTestRunner(t)
+
+	for want := 1; want <= 2; want++ {
+		response, err := runner.Run("hello", Request{Method: "POST", Body: map[string]int{"a": 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.StatusCode != 201 {
+			t.Fatalf("expected 201, got %d: %s", response.StatusCode, response.Error)
+		}
+		var payload struct {
+			Count int `json:"count"`
+		}
+		if err := json.Unmarshal(response.Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Count != want {
+			t.Errorf("expected count %d, got %d", want, payload.Count)
+		}
+	}
+}
+
+func TestRunnerErrors(t *testing.T) {
+	runner := newTestRunner(t)
+
+	response, err := runner.Run("hello", Request{Method: "PUT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != 500 || !strings.Contains(response.Error, "boom") {
+		t.Errorf("expected 500 with traceback, got %d %q", response.StatusCode, response.Error)
+	}
+
+	response, err = runner.Run("hello", Request{Method: "DELETE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != 405 {
+		t.Errorf("expected 405, got %d", response.StatusCode)
+	}
+
+	if _, err := runner.Run("missing", Request{Method: "GET"}); err == nil {
+		t.Error("expected an error for a missing endpoint file")
+	}
+}
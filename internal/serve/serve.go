@@ -0,0 +1,178 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// Server routes HTTP requests to endpoint functions run by a Runner. Each endpoint
+// is reachable both at its remote URI and at /<name>.
+type Server struct {
+	runner *Runner
+	routes map[string]string
+	t      *terminal.Terminal
+}
+
+// NewServer returns a Server for the given endpoints
+func NewServer(t *terminal.Terminal, runner *Runner, endpoints []brev_api.Endpoint) *Server {
+	routes := make(map[string]string)
+	for _, endpoint := range endpoints {
+		if endpoint.Uri != "" {
+			routes[endpoint.Uri] = endpoint.Name
+		}
+		routes["/"+endpoint.Name] = endpoint.Name
+	}
+	return &Server{
+		runner: runner,
+		routes: routes,
+		t:      t,
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	name, ok := s.routes[strings.TrimSuffix(r.URL.Path, "/")]
+	if !ok {
+		s.writeError(w, r, start, http.StatusNotFound, fmt.Sprintf("no endpoint at %s", r.URL.Path))
+		return
+	}
+
+	request := Request{
+		Method: r.Method,
+		Args:   make(map[string]string),
+	}
+	for key, values := range r.URL.Query() {
+		request.Args[key] = values[0]
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, r, start, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &request.Body); err != nil {
+			s.writeError(w, r, start, http.StatusBadRequest, fmt.Sprintf("failed to process JSON payload: %s", err))
+			return
+		}
+	}
+
+	response, err := s.runner.Run(name, request)
+	if err != nil {
+		s.writeError(w, r, start, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-stdout", encodeHeaderValue(response.Stdout))
+	w.WriteHeader(response.StatusCode)
+	if response.Error != "" {
+		json.NewEncoder(w).Encode(map[string]string{"error": response.Error})
+	} else {
+		w.Write(response.Payload)
+	}
+
+	s.logRequest(r, response.StatusCode, start)
+	if response.Stdout != "" {
+		s.t.Vprint(strings.TrimRight(response.Stdout, "\n"))
+	}
+	if response.Error != "" {
+		s.t.Vprint(redMessage(s.t, response.Error))
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, start time.Time, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	s.logRequest(r, status, start)
+	s.t.Vprint(redMessage(s.t, message))
+}
+
+func (s *Server) logRequest(r *http.Request, status int, start time.Time) {
+	statusStr := fmt.Sprintf("%d", status)
+	if status >= 400 {
+		statusStr = s.t.Red(statusStr)
+	} else {
+		statusStr = s.t.Green(statusStr)
+	}
+	s.t.Vprint(fmt.Sprintf("%s %-6s %s %s %s", start.Format("15:04:05"), r.Method, r.URL.RequestURI(), statusStr, time.Since(start).Round(time.Millisecond)))
+}
+
+func redMessage(t *terminal.Terminal, message string) string {
+	return t.Red("%s", strings.TrimRight(message, "\n"))
+}
+
+// encodeHeaderValue makes captured stdout safe to send as a header value
+func encodeHeaderValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "\\n").Replace(value)
+}
+
+func serve(t *terminal.Terminal, host string, port int, python string) error {
+	local, err := brev_ctx.NewLocal()
+	if err != nil {
+		return err
+	}
+
+	project, err := local.GetProject()
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	global, err := brev_ctx.NewGlobal()
+	if err != nil {
+		return err
+	}
+
+	paths, err := global.GetProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	runner := NewRunner(python, paths)
+	defer runner.Close()
+
+	address := fmt.Sprintf("%s:%d", host, port)
+	t.Vprint(t.Green("\nServing project %s locally at ", project.Name) + t.Yellow("http://%s", address))
+	for _, endpoint := range endpoints {
+		t.Vprint(fmt.Sprintf("\t%s:\thttp://%s/%s", t.Green(endpoint.Name), address, endpoint.Name))
+	}
+	t.Vprint("")
+
+	server := &http.Server{Addr: address, Handler: NewServer(t, runner, endpoints)}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-interrupt:
+		t.Vprint(t.Yellow("\nStopping local server"))
+		return server.Shutdown(context.Background())
+	}
+}
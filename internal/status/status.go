@@ -2,6 +2,7 @@ package status
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -11,7 +12,7 @@ import (
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func NewCmdStatus(t *terminal.Terminal) *cobra.Command {
+func NewCmdStatus(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:         "status",
@@ -25,11 +26,11 @@ func NewCmdStatus(t *terminal.Terminal) *cobra.Command {
 				return err
 			}
 
-			_, err = brev_api.CheckOutsideBrevErrorMessage(t)
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
 			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			status(t)
+			status(t, newClient)
 			return nil
 		},
 	}
@@ -37,9 +38,9 @@ func NewCmdStatus(t *terminal.Terminal) *cobra.Command {
 	return cmd
 }
 
-func status(t *terminal.Terminal) error {
+func status(t *terminal.Terminal, newClient brev_api.ClientFactory) error {
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -88,7 +89,11 @@ func status(t *terminal.Terminal) error {
 		t.Vprint(t.Yellow("\n\tEndpoints:"))
 
 		for _, v := range endpoints {
-			str := "\n\t\t" + t.Yellow("%s", v.Name) + "\n\t\t\t" + project.Domain + v.Uri
+			methods := "no methods declared"
+			if len(v.Methods) > 0 {
+				methods = strings.Join(v.Methods, ", ")
+			}
+			str := "\n\t\t" + t.Yellow("%s", v.Name) + " [" + methods + "]" + "\n\t\t\t" + project.Domain + v.Uri
 			t.Vprint(str)
 		}
 	}
@@ -4,27 +4,28 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
-	"github.com/andreyvit/diff"
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
 	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
+	"github.com/brevdev/brev-go-cli/internal/parallel"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func push(t *terminal.Terminal) error {
+func push(t *terminal.Terminal, newClient brev_api.ClientFactory, force bool, inferMethods bool, res resolution, concurrency int) error {
 
 	bar := t.NewProgressBar("Pushing code to the console", func() {})
 
 	bar.AdvanceTo(40)
 
-	path, err := getRootProjectDir(t)
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
 
-	brevCtx, err := brev_ctx.New()
+	paths, err := brevCtx.Global.GetProjectFiles()
 	if err != nil {
 		return err
 	}
@@ -34,64 +35,196 @@ func push(t *terminal.Terminal) error {
 		return err
 	}
 
-	// update module
-	bar.Describe(t.Green("Updating %s", "shared code"))
-
-	module, err := brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: project.Id})
+	manifest, err := brevCtx.Local.GetManifest()
 	if err != nil {
 		return err
 	}
-	module.Source, err = files.ReadString(fmt.Sprintf("%s/%s.py", path, module.Name))
+
+	module, err := brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: project.Id})
 	if err != nil {
 		return err
 	}
 
-	_, err = brevCtx.Remote.SetModule(&brev_ctx.SetModulesOptions{
+	localEndpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
 		ProjectID: project.Id,
-		ModuleID:  module.Id,
-		Source:    module.Source,
 	})
 	if err != nil {
 		return err
 	}
 
-	endpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+	remoteEndpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
 		ProjectID: project.Id,
 	})
 	if err != nil {
 		return err
 	}
 
+	syncFiles, err := collectSyncFiles(paths, manifest, module, localEndpoints, remoteEndpoints)
+	if err != nil {
+		return err
+	}
+
+	remoteEpMap := make(map[string]brev_api.Endpoint)
+	for _, v := range remoteEndpoints {
+		remoteEpMap[v.Id] = v
+	}
+
+	// decide on every file before touching anything
+	var uploads []syncFile
+	methods := make(map[string][]string)
+	var pulled []syncFile
+	numUnchanged := 0
+	for _, f := range syncFiles {
+		if !f.tracked {
+			t.Vprint(t.Yellow("Skipping %s, it was created remotely. Run `brev sync` to fetch it", f.name))
+			continue
+		}
+		if !f.localExists {
+			// push never deletes remote code
+			continue
+		}
+		if f.endpoint != nil && !f.remoteExists {
+			t.Vprint(t.Yellow("Skipping %s, it was deleted remotely. Run `brev sync` to update your endpoints", f.name))
+			continue
+		}
+
+		// with inferMethods, an endpoint whose handlers changed is uploaded even if its
+		// code did not; files defining no handlers keep their declared methods
+		methodsChanged := false
+		if f.endpoint != nil {
+			methods[f.name] = f.endpoint.Methods
+			if inferred := brev_api.InferMethods(f.local); inferMethods && len(inferred) > 0 {
+				methods[f.name] = inferred
+				methodsChanged = !brev_api.SameMethods(inferred, remoteEpMap[f.endpoint.Id].Methods)
+			}
+		}
+
+		upload := false
+		switch f.state {
+		case stateUnchanged:
+			upload = force || methodsChanged
+		case stateLocalChanged:
+			upload = true
+		case stateRemoteChanged:
+			upload = force
+			if !force {
+				t.Vprint(t.Yellow("Skipping %s, it changed remotely. Run `brev pull` to fetch it", f.name))
+			}
+		case stateConflict:
+			upload = res == resolveOurs
+			if res == resolveTheirs || res == resolveMarkers {
+				pulled = append(pulled, f)
+			}
+		}
+
+		if !upload {
+			if f.state == stateUnchanged {
+				numUnchanged += 1
+			}
+			continue
+		}
+		if hasConflictMarkers(f.local) {
+			return fmt.Errorf("%s still contains conflict markers, resolve them before pushing", f.name)
+		}
+		if methodsChanged {
+			t.Vprint(t.Yellow("%s now serves %s", f.name, strings.Join(methods[f.name], ", ")))
+		}
+		uploads = append(uploads, f)
+	}
+	err = checkConflicts(syncFiles, res)
+	if err != nil {
+		return err
+	}
+
 	t.Vprint("\n") // separating the below output from the loadingbar
-	for _, v := range endpoints {
-		t.Vprint(t.Green("Updating ep %s", v.Name))
-		v.Code, err = files.ReadString(fmt.Sprintf("%s/%s.py", path, v.Name))
+	uploaded := make([]bool, len(uploads))
+	uploadErr := parallel.Run(len(uploads), concurrency, func(i int) error {
+		f := uploads[i]
+		var err error
+		if f.endpoint == nil {
+			t.Vprint(t.Green("Updating %s", "shared code"))
+			_, err = brevCtx.Remote.SetModule(&brev_ctx.SetModulesOptions{
+				ProjectID: project.Id,
+				ModuleID:  module.Id,
+				Source:    f.local,
+			})
+		} else {
+			t.Vprint(t.Green("Updating ep %s", f.endpoint.Name))
+			_, err = brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
+				Id:      f.endpoint.Id,
+				Name:    f.endpoint.Name,
+				Methods: methods[f.name],
+				Code:    f.local,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		uploaded[i] = true
+		return nil
+	}, func(finished int) {
+		bar.AdvanceTo(40 + 60*finished/len(uploads))
+	})
+	// keep track of what made it, even if some uploads failed
+	pushedMethods := make(map[string][]string)
+	for i, f := range uploads {
+		if uploaded[i] {
+			manifest.Record(f.name, f.local)
+			if f.endpoint != nil {
+				pushedMethods[f.endpoint.Id] = methods[f.name]
+			}
+		}
+	}
+	if inferMethods && len(pushedMethods) > 0 {
+		for i, v := range localEndpoints {
+			if m, ok := pushedMethods[v.Id]; ok {
+				localEndpoints[i].Methods = m
+			}
+		}
+		err = brevCtx.Local.SetEndpoints(localEndpoints)
 		if err != nil {
 			return err
 		}
+	}
 
-		brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
-			Id:      v.Id,
-			Name:    v.Name,
-			Methods: v.Methods,
-			Code:    v.Code,
-		})
+	for _, f := range pulled {
+		err = resolveLocally(t, paths, f, res)
+		if err != nil {
+			brevCtx.Local.SetManifest(manifest)
+			return err
+		}
+		manifest.Record(f.name, f.remote)
+	}
 
+	err = brevCtx.Local.SetManifest(manifest)
+	if err != nil {
+		return err
+	}
+	if uploadErr != nil {
+		t.Errprint(uploadErr, "Failed to push some files")
+		return uploadErr
 	}
 
 	bar.AdvanceTo(100)
-	t.Vprint(t.Green("\n\nYour project is synced 🥞"))
+	if len(uploads) == 0 {
+		t.Vprint(t.Green("\n\nNothing to push 🥞"))
+		if numUnchanged > 0 {
+			t.Vprint(t.Yellow("Run `brev push --force` to upload unchanged files anyway"))
+		}
+		return nil
+	}
+	t.Vprint(t.Green("\n\nPushed %d file(s), %d unchanged. Your project is synced 🥞", len(uploads), numUnchanged))
 
 	return nil
 }
 
-func pull(t *terminal.Terminal) error {
+func pull(t *terminal.Terminal, newClient brev_api.ClientFactory, res resolution, concurrency int) error {
 
 	bar := t.NewProgressBar("Fetching code from the console", func() {})
 
 	bar.AdvanceTo(40)
 
-	brevCtx, err := brev_ctx.New()
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
@@ -108,7 +241,7 @@ func pull(t *terminal.Terminal) error {
 		return err
 	}
 
-	path, err := getRootProjectDir(t)
+	paths, err := brevCtx.Global.GetProjectFiles()
 	if err != nil {
 		return err
 	}
@@ -119,198 +252,225 @@ func pull(t *terminal.Terminal) error {
 	if err != nil {
 		return err
 	}
-	bar.Describe(t.Green("Pulling %s", module.Name))
-	bar.AdvanceTo(40)
 
-	err = files.OverwriteString(fmt.Sprintf("%s/%s.py", path, module.Name), module.Source)
+	manifest, err := brevCtx.Local.GetManifest()
+	if err != nil {
+		return err
+	}
 
-	t.Vprint("\n") // separating the below output from the loadingbar
-	for _, v := range remoteEndpoints {
-		t.Vprint(t.Green("Pulling ep %s", v.Name))
+	// local endpoints are left out, pull replaces them with the remote ones
+	syncFiles, err := collectSyncFiles(paths, manifest, module, nil, remoteEndpoints)
+	if err != nil {
+		return err
+	}
+	err = checkConflicts(syncFiles, res)
+	if err != nil {
+		return err
+	}
+	bar.Describe(t.Green("Pulling %s", project.Name))
+	bar.AdvanceTo(40)
 
-		err = files.OverwriteString(fmt.Sprintf("%s/%s.py", path, v.Name), v.Code)
-		if err != nil {
-			t.Errprint(err, "Failed to write code to local file")
-			return err
+	t.Vprint("\n") // separating the below output from the loadingbar
+	written := make([]bool, len(syncFiles))
+	writeErr := parallel.Run(len(syncFiles), concurrency, func(i int) error {
+		f := syncFiles[i]
+		switch f.state {
+		case stateLocalChanged:
+			t.Vprint(t.Yellow("Keeping local changes to %s", f.name))
+			return nil
+		case stateRemoteChanged:
+			if f.endpoint != nil {
+				t.Vprint(t.Green("Pulling ep %s", f.endpoint.Name))
+			}
+			err := files.OverwriteString(paths.Abs(f.name), f.remote)
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", f.name, err)
+			}
+		case stateConflict:
+			err := resolveLocally(t, paths, f, res)
+			if err != nil {
+				return err
+			}
+		}
+		written[i] = true
+		return nil
+	}, func(finished int) {
+		bar.AdvanceTo(40 + 60*finished/len(syncFiles))
+	})
+	for i, f := range syncFiles {
+		if written[i] {
+			manifest.Record(f.name, f.remote)
 		}
-		time.Sleep(100 * time.Millisecond)
-
 	}
-	bar.AdvanceTo(100)
 
 	brevCtx.Local.SetEndpoints(remoteEndpoints)
+	err = brevCtx.Local.SetManifest(manifest)
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		t.Errprint(writeErr, "Failed to pull some files")
+		return writeErr
+	}
 
 	t.Vprint(t.Green("\n\nYour project is synced 🥞"))
 
 	return nil
 }
 
-func getRootProjectDir(t *terminal.Terminal) (string, error) {
+// collectSyncFiles reads the local and remote contents of the module and of every
+// local or remote endpoint, and classifies how each changed since the last sync
+func collectSyncFiles(paths *layout.Paths, manifest brev_ctx.Manifest, module *brev_api.Module, localEndpoints []brev_api.Endpoint, remoteEndpoints []brev_api.Endpoint) ([]syncFile, error) {
+	syncFiles := []syncFile{{
+		name:         paths.Layout.ModuleFile(module.Name),
+		remote:       module.Source,
+		remoteExists: true,
+		tracked:      true,
+	}}
 
-	brevCtx, err := brev_ctx.New()
-	if err != nil {
-		return "", err
+	remoteEpMap := make(map[string]brev_api.Endpoint)
+	for _, v := range remoteEndpoints {
+		remoteEpMap[v.Id] = v
 	}
-
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Errprint(err, "Failed to determine working directory")
-		return "", err
+	// an endpoint in the module's file, e.g. after changing the layout, would be
+	// pushed as the module
+	for _, endpoints := range [][]brev_api.Endpoint{localEndpoints, remoteEndpoints} {
+		for _, v := range endpoints {
+			err := paths.Layout.CheckEndpoint(v.Name, module.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	paths, err := brevCtx.Global.GetProjectPaths()
-	if err != nil {
-		return "", err
+	seen := make(map[string]bool)
+	for _, v := range localEndpoints {
+		endpoint := v
+		remote, remoteExists := remoteEpMap[v.Id]
+		syncFiles = append(syncFiles, syncFile{
+			name:         paths.Layout.EndpointFile(v.Name),
+			remote:       remote.Code,
+			remoteExists: remoteExists,
+			endpoint:     &endpoint,
+			tracked:      true,
+		})
+		seen[v.Id] = true
+	}
+	for _, v := range remoteEndpoints {
+		if seen[v.Id] {
+			continue
+		}
+		endpoint := v
+		syncFiles = append(syncFiles, syncFile{
+			name:         paths.Layout.EndpointFile(v.Name),
+			remote:       v.Code,
+			remoteExists: true,
+			endpoint:     &endpoint,
+		})
 	}
 
-	var path string
-	for _, v := range paths {
-		if strings.Contains(cwd, v) {
-			path = v
+	for i, f := range syncFiles {
+		local, err := files.ReadString(paths.Abs(f.name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		syncFiles[i].local = local
+		syncFiles[i].localExists = err == nil
+
+		switch {
+		case !syncFiles[i].localExists:
+			syncFiles[i].state = stateRemoteChanged
+		case !f.remoteExists:
+			syncFiles[i].state = stateLocalChanged
+		default:
+			syncFiles[i].state = classify(manifest, f.name, local, f.remote)
 		}
 	}
-	return path, nil
+	return syncFiles, nil
 }
 
-func diffCmd(t *terminal.Terminal) error {
-
-	bar := t.NewProgressBar("Checking with the console", func() {})
-	bar.AdvanceTo(30)
-
-	numChanges := 0
-
-	brevCtx, err := brev_ctx.New()
-	if err != nil {
-		return err
+// resolveLocally settles a conflicting file by taking the remote contents or by
+// writing both versions with conflict markers. Callers record the remote contents
+// as the new base, so the settled file is pushed as a local change.
+func resolveLocally(t *terminal.Terminal, paths *layout.Paths, f syncFile, res resolution) error {
+	filePath := paths.Abs(f.name)
+	switch res {
+	case resolveTheirs:
+		t.Vprint(t.Yellow("Conflict in %s, taking the remote version", f.name))
+		err := files.OverwriteString(filePath, f.remote)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	case resolveMarkers:
+		t.Vprint(t.Red("Conflict in %s, wrote conflict markers to resolve by hand", f.name))
+		err := files.OverwriteString(filePath, conflictMarkers(f.local, f.remote))
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	case resolveOurs:
+		t.Vprint(t.Yellow("Conflict in %s, keeping the local version", f.name))
 	}
+	return nil
+}
 
-	project, err := brevCtx.Local.GetProject()
-	if err != nil {
-		return err
-	}
+func diffCmd(t *terminal.Terminal, newClient brev_api.ClientFactory, options diffOptions) error {
 
-	// Diff Shared Code/Module
-	path, err := getRootProjectDir(t)
-	if err != nil {
-		return err
+	// machine-readable output must not be mixed with the progress bar
+	var bar *terminal.ProgressBar
+	if options.format == diffFormatText && !options.nameOnly {
+		bar = t.NewProgressBar("Checking with the console", func() {})
+		bar.AdvanceTo(30)
 	}
 
-	module, err := brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: project.Id})
+	brevCtx, err := brev_ctx.New(newClient)
 	if err != nil {
 		return err
 	}
 
-	bar.AdvanceTo(30)
-	remoteEps, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
-		ProjectID: project.Id,
-	})
-	if err != nil {
-		return err
-	}
-	bar.AdvanceTo(100)
-
-	localModule, err := files.ReadString(fmt.Sprintf("%s/%s.py", path, module.Name))
+	project, err := brevCtx.Local.GetProject()
 	if err != nil {
 		return err
 	}
-	t.Vprint(t.Yellow("\nDiff for Project %s :", project.Name))
 
-	moduleDiff := diffTwoFiles(module.Source, localModule)
-	diffString := printDiff("Shared", moduleDiff, t)
-	if len(diffString) > 0 {
-		t.Vprint(diffString)
-		numChanges += 1
-	}
-
-	// Diff Endpoints
-	localEps, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
-		ProjectID: project.Id,
-	})
-	var localEPIds []string
-	for _, v := range localEps {
-		localEPIds = append(localEPIds, v.Id)
-	}
+	// scripts checking for drift are told about whitespace changes as well
+	diffs, err := collectDiffs(brevCtx, project, options.context, options.exitCode)
 	if err != nil {
 		return err
 	}
-
-	var remoteEPIds []string
-	remoteEpMap := make(map[string]brev_api.Endpoint)
-	for _, v := range remoteEps {
-		remoteEPIds = append(remoteEPIds, v.Id)
-		remoteEpMap[v.Id] = v
+	if bar != nil {
+		bar.AdvanceTo(100)
 	}
 
-	// per local endpoint, diff the remote contents
-	for _, v := range localEps {
-		// if the local ep has a remote counter part, run a diff
-		if brev_api.StringInList(v.Id, remoteEPIds) {
-			path, err := getRootProjectDir(t)
-			if err != nil {
-				return err
-			}
-
-			v.Code, err = files.ReadString(fmt.Sprintf("%s/%s.py", path, v.Name))
-			if err != nil {
-				return err
-			}
-			diff := diffTwoFiles(remoteEpMap[v.Id].Code, v.Code)
-			diffString := printDiff(v.Name, diff, t)
-			if len(diffString) > 0 {
-				t.Vprint(diffString)
-				numChanges += 1
-			}
-		} else {
-			// The endpoint doesn't exist in remote
-			diff := diffTwoFiles("", v.Code)
-			diffString := printDiff(v.Name, diff, t)
-			if len(diffString) > 0 {
-				t.Vprint(diffString)
-				numChanges += 1
-			}
+	switch {
+	case options.nameOnly:
+		for _, d := range diffs {
+			t.Vprint(d.File)
 		}
-	}
-	// if remote endpoint isn't local, then it needs to be pulled
-	for _, v := range remoteEps {
-		if !brev_api.StringInList(v.Id, localEPIds) {
-			diff := diffTwoFiles(remoteEpMap[v.Id].Code, "")
-			diffString := printDiff(v.Name, diff, t)
-			if len(diffString) > 0 {
-				t.Vprint(diffString)
-				numChanges += 1
-			}
+	case options.format == diffFormatJSON:
+		err = printDiffJSON(t, project, diffs)
+		if err != nil {
+			return err
+		}
+	case options.format == diffFormatUnified:
+		for _, d := range diffs {
+			t.Vprintf("%s", d.unified())
+		}
+	default:
+		width := 0
+		if options.sideBySide {
+			width = terminalWidth()
+		}
+		t.Vprint(t.Yellow("\nDiff for Project %s :", project.Name))
+		for _, d := range diffs {
+			t.Vprint(renderDiff(t, d, width))
+		}
+		if len(diffs) == 0 {
+			t.Vprint(t.Green("All Synced 🥞"))
 		}
 	}
 
-	if numChanges == 0 {
-		t.Vprint(t.Green("All Synced 🥞"))
+	if options.exitCode && len(diffs) > 0 {
+		return &brev_errors.SuppressedError{}
 	}
-
 	return nil
 }
-
-func diffTwoFiles(s1 string, s2 string) string {
-	s1Trimmed := strings.TrimSpace(s1)
-	s2Trimmed := strings.TrimSpace(s2)
-	return diff.LineDiff(s1Trimmed, s2Trimmed)
-
-}
-
-func printDiff(filename string, diff string, t *terminal.Terminal) string {
-
-	diffOutputString := ""
-	totalDiffLines := 0
-	for _, v := range strings.Split(diff, "\n") {
-		if strings.Compare(string(v[0]), "+") == 0 {
-			diffOutputString += "\n" + t.Green(v)
-			totalDiffLines += 1
-		} else if strings.Compare(string(v[0]), "-") == 0 {
-			diffOutputString += "\n" + t.Red(v)
-			totalDiffLines += 1
-		}
-	}
-	if totalDiffLines > 0 {
-		diffOutputString = t.Yellow("%s.py: ", filename) + diffOutputString + "\n"
-	}
-	return diffOutputString
-}
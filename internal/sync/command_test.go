@@ -0,0 +1,329 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_api/brevtest"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/dryrun"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func TestPush(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 1\n")
+	files.OverwriteString(filepath.Join(path, "shared.py"), "X = 1\n")
+
+	if err := push(terminal.New(), fake.Factory(), false, false, resolveNone, 4); err != nil {
+		t.Fatalf("push() returned error: %v", err)
+	}
+
+	want := map[string]string{
+		"hello": "def get():\n    return 1\n",
+		"world": fake.Endpoints[1].Code,
+	}
+	for _, endpoint := range fake.Endpoints {
+		if endpoint.Code != want[endpoint.Name] {
+			t.Errorf("remote code for %s = %q, want %q", endpoint.Name, endpoint.Code, want[endpoint.Name])
+		}
+	}
+	if fake.Modules[0].Source != "X = 1\n" {
+		t.Errorf("remote module source = %q, want %q", fake.Modules[0].Source, "X = 1\n")
+	}
+}
+
+func TestPull(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello")
+
+	fake.Endpoints[0].Code = "def get():\n    return 2\n"
+	fake.Modules[0].Source = "Y = 2\n"
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+
+	for file, want := range map[string]string{
+		"hello.py":  "def get():\n    return 2\n",
+		"shared.py": "Y = 2\n",
+	} {
+		got, err := files.ReadString(filepath.Join(path, file))
+		if err != nil || got != want {
+			t.Errorf("%s = %q, %v, want %q", file, got, err, want)
+		}
+	}
+}
+
+func TestPushOnlyUploadsChangedFiles(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+
+	// remote edits reveal which files a push uploads
+	fake.Endpoints[1].Code = "remote edit"
+	fake.Modules[0].Source = "remote edit"
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 1\n")
+
+	if err := push(terminal.New(), fake.Factory(), false, false, resolveNone, 4); err != nil {
+		t.Fatalf("push() returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != "def get():\n    return 1\n" {
+		t.Errorf("changed hello.py was not pushed, remote code = %q", fake.Endpoints[0].Code)
+	}
+	if fake.Endpoints[1].Code != "remote edit" || fake.Modules[0].Source != "remote edit" {
+		t.Errorf("unchanged files were pushed")
+	}
+
+	if err := push(terminal.New(), fake.Factory(), true, false, resolveNone, 4); err != nil {
+		t.Fatalf("push() with force returned error: %v", err)
+	}
+	if fake.Endpoints[1].Code == "remote edit" || fake.Modules[0].Source == "remote edit" {
+		t.Errorf("forced push did not upload unchanged files")
+	}
+}
+
+func TestPushInferMethods(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+	// hello only changes which handlers it defines, world keeps its code
+	fake.Endpoints[0].Methods = []string{"GET"}
+	files.OverwriteString(filepath.Join(path, "world.py"), "def put():\n    return 1\n")
+
+	if err := push(terminal.New(), fake.Factory(), false, true, resolveNone, 4); err != nil {
+		t.Fatalf("push() returned error: %v", err)
+	}
+	if want := []string{"GET", "POST"}; !reflect.DeepEqual(fake.Endpoints[0].Methods, want) {
+		t.Errorf("remote methods of hello = %v, want %v", fake.Endpoints[0].Methods, want)
+	}
+	if want := []string{"PUT"}; !reflect.DeepEqual(fake.Endpoints[1].Methods, want) {
+		t.Errorf("remote methods of world = %v, want %v", fake.Endpoints[1].Methods, want)
+	}
+
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+	endpoints, _ := brevCtx.Local.GetEndpoints(nil)
+	if !reflect.DeepEqual(endpoints[1].Methods, []string{"PUT"}) {
+		t.Errorf("local methods of world = %v, want [PUT]", endpoints[1].Methods)
+	}
+}
+
+func TestPushDryRun(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	remoteCode := fake.Endpoints[0].Code
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 1\n")
+	manifestPath := filepath.Join(path, ".brev", "manifest.json")
+	manifest, _ := files.ReadString(manifestPath)
+
+	plan := dryrun.NewPlan()
+	newClient := plan.Factory(fake.Factory())
+	plan.Start()
+	defer files.SetRecorder(nil)
+
+	if err := push(terminal.New(), newClient, false, false, resolveNone, 4); err != nil {
+		t.Fatalf("push() returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != remoteCode {
+		t.Errorf("dry run pushed hello")
+	}
+	if after, _ := files.ReadString(manifestPath); after != manifest {
+		t.Errorf("dry run wrote the manifest")
+	}
+
+	var actions []string
+	for _, step := range plan.Steps() {
+		actions = append(actions, step.Action+" "+step.Target)
+	}
+	want := []string{
+		"PUT /_api/_endpoint/" + fake.Endpoints[0].Id,
+		"write " + filepath.Join(".brev", "manifest.json"),
+	}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("planned steps = %v, want %v", actions, want)
+	}
+}
+
+func TestSyncWithLayout(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello")
+
+	files.OverwriteString(filepath.Join(path, ".brev", "layout.json"), `{"endpoints": "endpoints/{name}.py", "modules": "lib/{name}.py"}`)
+	fake.Modules[0].Source = "X = 1\n"
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+	for _, file := range []string{"endpoints/hello.py", "lib/shared.py"} {
+		if exists, _ := files.Exists(filepath.Join(path, file)); !exists {
+			t.Errorf("pull() did not write %s", file)
+		}
+	}
+
+	files.OverwriteString(filepath.Join(path, "endpoints", "hello.py"), "def get():\n    return 1\n")
+	if err := push(terminal.New(), fake.Factory(), false, false, resolveNone, 4); err != nil {
+		t.Fatalf("push() returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != "def get():\n    return 1\n" {
+		t.Errorf("push() did not upload endpoints/hello.py, remote code = %q", fake.Endpoints[0].Code)
+	}
+
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+	manifest, _ := brevCtx.Local.GetManifest()
+	if !manifest.Unchanged("endpoints/hello.py", fake.Endpoints[0].Code) {
+		t.Errorf("manifest = %v, want entries keyed by path relative to the project root", manifest)
+	}
+}
+
+func TestPullConflict(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello")
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+
+	helloPath := filepath.Join(path, "hello.py")
+	files.OverwriteString(helloPath, "def get():\n    return 1\n")
+	fake.Endpoints[0].Code = "def get():\n    return 2\n"
+
+	err := pull(terminal.New(), fake.Factory(), resolveNone, 4)
+	var conflict *brev_errors.SyncConflictError
+	if !errors.As(err, &conflict) || len(conflict.Files) != 1 || conflict.Files[0] != "hello.py" {
+		t.Fatalf("pull() error = %v, want a conflict in hello.py", err)
+	}
+	if err := push(terminal.New(), fake.Factory(), false, false, resolveNone, 4); !errors.As(err, &conflict) {
+		t.Fatalf("push() error = %v, want a conflict", err)
+	}
+	if got, _ := files.ReadString(helloPath); got != "def get():\n    return 1\n" {
+		t.Errorf("conflicting local file was modified: %q", got)
+	}
+
+	if err := pull(terminal.New(), fake.Factory(), resolveMarkers, 4); err != nil {
+		t.Fatalf("pull() with markers returned error: %v", err)
+	}
+	got, _ := files.ReadString(helloPath)
+	if !hasConflictMarkers(got) {
+		t.Errorf("expected conflict markers in %q", got)
+	}
+	if err := push(terminal.New(), fake.Factory(), false, false, resolveNone, 4); err == nil {
+		t.Error("push() accepted a file with conflict markers")
+	}
+
+	// settling the file by hand makes it a plain local change
+	files.OverwriteString(helloPath, "def get():\n    return 3\n")
+	if err := push(terminal.New(), fake.Factory(), false, false, resolveNone, 4); err != nil {
+		t.Fatalf("push() returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != "def get():\n    return 3\n" {
+		t.Errorf("remote code = %q", fake.Endpoints[0].Code)
+	}
+}
+
+func TestPullWithoutManifest(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	// projects from before the manifest have no record of what was last synced
+	os.Remove(filepath.Join(path, ".brev", "manifest.json"))
+	helloPath := filepath.Join(path, "hello.py")
+	local := "def get():\n    return 1\n"
+	files.OverwriteString(helloPath, local)
+	fake.Endpoints[0].Code = "def get():\n    return 2\n"
+
+	err := pull(terminal.New(), fake.Factory(), resolveNone, 4)
+	var conflict *brev_errors.SyncConflictError
+	if !errors.As(err, &conflict) || !reflect.DeepEqual(conflict.Files, []string{"hello.py"}) {
+		t.Fatalf("pull() error = %v, want a conflict in hello.py only", err)
+	}
+	if got, _ := files.ReadString(helloPath); got != local {
+		t.Errorf("pull() overwrote the local edit with %q", got)
+	}
+
+	if err := pull(terminal.New(), fake.Factory(), resolveOurs, 4); err != nil {
+		t.Fatalf("pull() with --ours returned error: %v", err)
+	}
+	if got, _ := files.ReadString(helloPath); got != local {
+		t.Errorf("pull() --ours overwrote the local edit with %q", got)
+	}
+}
+
+func TestPullRefusesEndpointInModuleFile(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake, "hello")
+
+	fake.CreateEndpoint("shared", project.Id, nil, "def get():\n    return 1\n")
+	if err := pull(terminal.New(), fake.Factory(), resolveTheirs, 4); err == nil {
+		t.Fatalf("pull() accepted an endpoint named after the module")
+	}
+	if got, _ := files.ReadString(filepath.Join(path, "shared.py")); got != "" {
+		t.Errorf("pull() wrote the endpoint into the module's file: %q", got)
+	}
+}
+
+func TestCollectDiffs(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	fake.CreateEndpoint("remote_only", project.Id, nil, "")
+	fake.Endpoints[0].Code = "def get():\n    return 1\n"
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 2\n")
+
+	brevCtx, err := brev_ctx.New(fake.Factory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := collectDiffs(brevCtx, &project, 3, false)
+	if err != nil {
+		t.Fatalf("collectDiffs() returned error: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, d := range diffs {
+		got[d.File] = d.Status
+	}
+	want := map[string]string{"hello.py": diffModified, "remote_only.py": diffRemoved}
+	if len(got) != len(want) || got["hello.py"] != want["hello.py"] || got["remote_only.py"] != want["remote_only.py"] {
+		t.Fatalf("diff statuses = %v, want %v", got, want)
+	}
+
+	wantPatch := "--- a/hello.py\n+++ b/hello.py\n@@ -1,2 +1,2 @@\n def get():\n-    return 1\n+    return 2\n"
+	if patch := diffs[0].unified(); patch != wantPatch {
+		t.Errorf("unified() =\n%s\nwant\n%s", patch, wantPatch)
+	}
+}
+
+func TestCollectDiffsWhitespace(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake, "hello")
+
+	fake.Endpoints[0].Code = "def get():\n    return 1\n"
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 1\n\n")
+
+	brevCtx, err := brev_ctx.New(fake.Factory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs, err := collectDiffs(brevCtx, &project, 3, false); err != nil || len(diffs) != 0 {
+		t.Errorf("collectDiffs() = %v, %v, want the trailing blank line ignored", diffs, err)
+	}
+	// --exit-code compares exact contents
+	if diffs, err := collectDiffs(brevCtx, &project, 3, true); err != nil || len(diffs) != 1 {
+		t.Errorf("collectDiffs() = %v, %v, want the trailing blank line reported", diffs, err)
+	}
+}
@@ -0,0 +1,169 @@
+package sync
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+)
+
+const (
+	markerLocal  = "<<<<<<< local"
+	markerSplit  = "======="
+	markerRemote = ">>>>>>> remote"
+)
+
+// syncState describes how a file changed relative to the base recorded in the manifest
+type syncState int
+
+const (
+	stateUnchanged syncState = iota
+	stateLocalChanged
+	stateRemoteChanged
+	stateConflict
+)
+
+// resolution is the way to settle files changed both locally and remotely
+type resolution int
+
+const (
+	resolveNone resolution = iota
+	resolveOurs
+	resolveTheirs
+	resolveMarkers
+)
+
+// conflictFlags holds the conflict resolution flags shared by push and pull
+type conflictFlags struct {
+	ours    bool
+	theirs  bool
+	markers bool
+}
+
+func (f *conflictFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.ours, "ours", false, "settle conflicts by keeping the local version")
+	cmd.Flags().BoolVar(&f.theirs, "theirs", false, "settle conflicts by taking the remote version")
+	cmd.Flags().BoolVar(&f.markers, "markers", false, "settle conflicts by writing both versions with git-style conflict markers")
+}
+
+func (f conflictFlags) resolution() (resolution, error) {
+	chosen := 0
+	res := resolveNone
+	if f.ours {
+		chosen += 1
+		res = resolveOurs
+	}
+	if f.theirs {
+		chosen += 1
+		res = resolveTheirs
+	}
+	if f.markers {
+		chosen += 1
+		res = resolveMarkers
+	}
+	if chosen > 1 {
+		return resolveNone, errors.New("only one of --ours, --theirs and --markers may be given")
+	}
+	return res, nil
+}
+
+// syncFile is a file of the project along with its local and remote contents
+type syncFile struct {
+	name         string
+	local        string
+	localExists  bool
+	remote       string
+	remoteExists bool
+
+	// endpoint is nil for the shared module
+	endpoint *brev_api.Endpoint
+	// tracked is false for endpoints missing from .brev/endpoints.json
+	tracked bool
+	state   syncState
+}
+
+// classify compares the local and remote contents of a file against the base recorded
+// in the manifest
+func classify(manifest brev_ctx.Manifest, fileName string, local string, remote string) syncState {
+	if local == remote {
+		return stateUnchanged
+	}
+	if _, ok := manifest[fileName]; !ok {
+		// without a recorded base, e.g. in projects from before the manifest, there is
+		// no telling which side changed
+		return stateConflict
+	}
+
+	localChanged := !manifest.Unchanged(fileName, local)
+	remoteChanged := !manifest.Unchanged(fileName, remote)
+	switch {
+	case localChanged && remoteChanged:
+		return stateConflict
+	case remoteChanged:
+		return stateRemoteChanged
+	case localChanged:
+		return stateLocalChanged
+	default:
+		return stateUnchanged
+	}
+}
+
+// checkConflicts returns a SyncConflictError naming the conflicting files, unless
+// a resolution was chosen
+func checkConflicts(syncFiles []syncFile, res resolution) error {
+	if res != resolveNone {
+		return nil
+	}
+	var conflicts []string
+	for _, f := range syncFiles {
+		if f.state == stateConflict {
+			conflicts = append(conflicts, f.name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &brev_errors.SyncConflictError{Files: conflicts}
+	}
+	return nil
+}
+
+// conflictMarkers merges the local and remote contents git-style: lines common to the
+// start and end of both are kept once and the differing lines in between are wrapped
+// in conflict markers.
+func conflictMarkers(local string, remote string) string {
+	localLines := strings.Split(strings.TrimSuffix(local, "\n"), "\n")
+	remoteLines := strings.Split(strings.TrimSuffix(remote, "\n"), "\n")
+
+	prefix := 0
+	for prefix < len(localLines) && prefix < len(remoteLines) && localLines[prefix] == remoteLines[prefix] {
+		prefix += 1
+	}
+	suffix := 0
+	for suffix < len(localLines)-prefix && suffix < len(remoteLines)-prefix &&
+		localLines[len(localLines)-1-suffix] == remoteLines[len(remoteLines)-1-suffix] {
+		suffix += 1
+	}
+
+	var merged []string
+	merged = append(merged, localLines[:prefix]...)
+	merged = append(merged, markerLocal)
+	merged = append(merged, localLines[prefix:len(localLines)-suffix]...)
+	merged = append(merged, markerSplit)
+	merged = append(merged, remoteLines[prefix:len(remoteLines)-suffix]...)
+	merged = append(merged, markerRemote)
+	merged = append(merged, localLines[len(localLines)-suffix:]...)
+	return strings.Join(merged, "\n") + "\n"
+}
+
+// hasConflictMarkers reports whether the contents still hold unresolved conflict markers
+func hasConflictMarkers(contents string) bool {
+	for _, line := range strings.Split(contents, "\n") {
+		if line == markerLocal || line == markerRemote {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+)
+
+func TestClassify(t *testing.T) {
+	manifest := make(brev_ctx.Manifest)
+	manifest.Record("a.py", "base")
+
+	cases := []struct {
+		file   string
+		local  string
+		remote string
+		want   syncState
+	}{
+		{"a.py", "base", "base", stateUnchanged},
+		{"a.py", "mine", "mine", stateUnchanged},
+		{"a.py", "mine", "base", stateLocalChanged},
+		{"a.py", "base", "theirs", stateRemoteChanged},
+		{"a.py", "mine", "theirs", stateConflict},
+		{"b.py", "mine", "theirs", stateConflict},
+		{"b.py", "same", "same", stateUnchanged},
+	}
+	for _, c := range cases {
+		if got := classify(manifest, c.file, c.local, c.remote); got != c.want {
+			t.Errorf("classify(%s, %q, %q) = %v, want %v", c.file, c.local, c.remote, got, c.want)
+		}
+	}
+}
+
+func TestConflictMarkers(t *testing.T) {
+	local := "import x\n\ndef get():\n    return 1\n\n# end\n"
+	remote := "import x\n\ndef get():\n    return 2\n\n# end\n"
+
+	want := "import x\n\ndef get():\n" +
+		"<<<<<<< local\n    return 1\n=======\n    return 2\n>>>>>>> remote\n" +
+		"\n# end\n"
+	got := conflictMarkers(local, remote)
+	if got != want {
+		t.Errorf("conflictMarkers() =\n%s\nwant\n%s", got, want)
+	}
+	if !hasConflictMarkers(got) {
+		t.Error("hasConflictMarkers() = false for merged contents")
+	}
+	if hasConflictMarkers(local) {
+		t.Error("hasConflictMarkers() = true for clean contents")
+	}
+}
+
+func TestConflictFlags(t *testing.T) {
+	if _, err := (conflictFlags{ours: true, theirs: true}).resolution(); err == nil {
+		t.Error("expected an error for --ours with --theirs")
+	}
+	if res, err := (conflictFlags{markers: true}).resolution(); err != nil || res != resolveMarkers {
+		t.Errorf("resolution() = %v, %v, want markers", res, err)
+	}
+}
@@ -0,0 +1,165 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+	"github.com/brevdev/brev-go-cli/internal/textdiff"
+)
+
+const (
+	diffFormatText    = "text"
+	diffFormatUnified = "unified"
+	diffFormatJSON    = "json"
+
+	diffAdded    = "added"
+	diffRemoved  = "removed"
+	diffModified = "modified"
+)
+
+type diffOptions struct {
+	format     string
+	context    int
+	nameOnly   bool
+	exitCode   bool
+	sideBySide bool
+}
+
+// fileDiff describes how a local file differs from the deployed code. Added files
+// only exist locally and removed files only exist remotely.
+type fileDiff struct {
+	Name   string     `json:"name"`
+	File   string     `json:"file"`
+	Status string     `json:"status"`
+	Hunks  []diffHunk `json:"hunks"`
+
+	remote string
+	local  string
+	hunks  []textdiff.Hunk
+}
+
+type diffHunk struct {
+	OldStart int      `json:"old_start"`
+	OldLines int      `json:"old_lines"`
+	NewStart int      `json:"new_start"`
+	NewLines int      `json:"new_lines"`
+	Lines    []string `json:"lines"`
+}
+
+// terminalWidth returns the width of the terminal, or a width suited to most
+// screens when the output is not a terminal
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return 160
+	}
+	return width
+}
+
+// collectDiffs compares the module and every endpoint with its deployed code.
+// Whitespace at the start and end of a file is ignored unless exact is set.
+func collectDiffs(brevCtx *brev_ctx.BrevContext, project *brev_api.Project, context int, exact bool) ([]fileDiff, error) {
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: project.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	localEndpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	remoteEndpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	syncFiles, err := collectSyncFiles(paths, nil, module, localEndpoints, remoteEndpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []fileDiff{}
+	for _, f := range syncFiles {
+		if f.local == f.remote || (!exact && strings.TrimSpace(f.local) == strings.TrimSpace(f.remote)) {
+			continue
+		}
+
+		d := fileDiff{
+			Name:   module.Name,
+			File:   f.name,
+			Status: diffModified,
+			remote: f.remote,
+			local:  f.local,
+		}
+		if f.endpoint != nil {
+			d.Name = f.endpoint.Name
+		}
+		if !f.remoteExists {
+			d.Status = diffAdded
+		} else if !f.localExists {
+			d.Status = diffRemoved
+		}
+
+		d.hunks = textdiff.Hunks(textdiff.Diff(d.remote, d.local), context)
+		for _, hunk := range d.hunks {
+			h := diffHunk{
+				OldStart: hunk.OldStart,
+				OldLines: hunk.OldLines,
+				NewStart: hunk.NewStart,
+				NewLines: hunk.NewLines,
+			}
+			for _, line := range hunk.Lines {
+				h.Lines = append(h.Lines, line.Op.Prefix()+strings.TrimSuffix(line.Text, "\n"))
+			}
+			d.Hunks = append(d.Hunks, h)
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// unified renders the diff so that applying it to the deployed code yields the local code
+func (d fileDiff) unified() string {
+	oldPath := "a/" + d.File
+	newPath := "b/" + d.File
+	if d.Status == diffAdded {
+		oldPath = "/dev/null"
+	} else if d.Status == diffRemoved {
+		newPath = "/dev/null"
+	}
+	return textdiff.Unified(oldPath, newPath, d.hunks)
+}
+
+func printDiffJSON(t *terminal.Terminal, project *brev_api.Project, diffs []fileDiff) error {
+	output, err := json.MarshalIndent(struct {
+		Project string     `json:"project"`
+		Drift   bool       `json:"drift"`
+		Files   []fileDiff `json:"files"`
+	}{
+		Project: project.Name,
+		Drift:   len(diffs) > 0,
+		Files:   diffs,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode diff: %w", err)
+	}
+	t.Vprint(string(output))
+	return nil
+}
@@ -0,0 +1,315 @@
+package sync
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// endpointRename is an endpoint whose name changed remotely
+type endpointRename struct {
+	from brev_api.Endpoint
+	to   brev_api.Endpoint
+}
+
+// reconcilePlan lists the changes bringing the local endpoints in line with the
+// remote ones. Endpoints are matched by ID.
+type reconcilePlan struct {
+	add    []brev_api.Endpoint
+	remove []brev_api.Endpoint
+	rename []endpointRename
+
+	// endpoints is the new content of .brev/endpoints.json
+	endpoints []brev_api.Endpoint
+}
+
+func planReconcile(localEndpoints []brev_api.Endpoint, remoteEndpoints []brev_api.Endpoint) reconcilePlan {
+	plan := reconcilePlan{endpoints: remoteEndpoints}
+
+	localEpMap := make(map[string]brev_api.Endpoint)
+	for _, v := range localEndpoints {
+		localEpMap[v.Id] = v
+	}
+	remoteEpMap := make(map[string]brev_api.Endpoint)
+	for _, v := range remoteEndpoints {
+		remoteEpMap[v.Id] = v
+	}
+
+	for _, v := range remoteEndpoints {
+		local, ok := localEpMap[v.Id]
+		if !ok {
+			plan.add = append(plan.add, v)
+		} else if local.Name != v.Name {
+			plan.rename = append(plan.rename, endpointRename{from: local, to: v})
+		}
+	}
+	for _, v := range localEndpoints {
+		if _, ok := remoteEpMap[v.Id]; !ok {
+			plan.remove = append(plan.remove, v)
+		}
+	}
+
+	sort.Slice(plan.add, func(i, j int) bool { return plan.add[i].Name < plan.add[j].Name })
+	sort.Slice(plan.remove, func(i, j int) bool { return plan.remove[i].Name < plan.remove[j].Name })
+	sort.Slice(plan.rename, func(i, j int) bool { return plan.rename[i].to.Name < plan.rename[j].to.Name })
+	return plan
+}
+
+func (p reconcilePlan) empty() bool {
+	return len(p.add) == 0 && len(p.remove) == 0 && len(p.rename) == 0
+}
+
+func (p reconcilePlan) print(t *terminal.Terminal, l layout.Layout) {
+	for _, v := range p.add {
+		t.Vprint(t.Green("  + add     %s", l.EndpointFile(v.Name)) + " (created remotely)")
+	}
+	for _, v := range p.rename {
+		t.Vprint(t.Yellow("  ~ rename  %s -> %s", l.EndpointFile(v.from.Name), l.EndpointFile(v.to.Name)))
+	}
+	for _, v := range p.remove {
+		t.Vprint(t.Red("  - delete  %s", l.EndpointFile(v.Name)) + " (deleted remotely)")
+	}
+}
+
+// check makes sure applying the plan loses no local work: new endpoints must not
+// overwrite untracked files, and deleted endpoints must not have unpushed changes
+// unless force is set.
+func (p reconcilePlan) check(paths *layout.Paths, manifest brev_ctx.Manifest, force bool) error {
+	renamedFrom := make(map[string]bool)
+	for _, v := range p.rename {
+		renamedFrom[paths.Layout.EndpointFile(v.from.Name)] = true
+	}
+	removed := make(map[string]bool)
+	for _, v := range p.remove {
+		removed[paths.Layout.EndpointFile(v.Name)] = true
+	}
+
+	targets := make([]brev_api.Endpoint, 0, len(p.add)+len(p.rename))
+	targets = append(targets, p.add...)
+	for _, v := range p.rename {
+		targets = append(targets, v.to)
+	}
+	for _, v := range targets {
+		fileName := paths.Layout.EndpointFile(v.Name)
+		if renamedFrom[fileName] || removed[fileName] {
+			continue
+		}
+		exists, err := files.Exists(paths.Abs(fileName))
+		if err != nil {
+			return err
+		}
+		if exists && !force {
+			return fmt.Errorf("%s already exists and is not a tracked endpoint; move it away or rerun with --force", fileName)
+		}
+	}
+
+	for _, v := range p.remove {
+		fileName := paths.Layout.EndpointFile(v.Name)
+		contents, err := files.ReadString(paths.Abs(fileName))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !manifest.Unchanged(fileName, contents) && !force {
+			return fmt.Errorf("%s was deleted remotely but has unpushed local changes; rerun with --force to delete it anyway", fileName)
+		}
+	}
+	return nil
+}
+
+// apply carries out the plan. If any step fails, every completed step is undone.
+func (p reconcilePlan) apply(brevCtx *brev_ctx.BrevContext, paths *layout.Paths, localEndpoints []brev_api.Endpoint, manifest brev_ctx.Manifest) (err error) {
+	tx := &fileTransaction{}
+	defer func() {
+		if err != nil {
+			tx.rollback()
+		}
+	}()
+
+	newManifest := make(brev_ctx.Manifest)
+	for fileName, hash := range manifest {
+		newManifest[fileName] = hash
+	}
+
+	// renames go through temporary names so that endpoints may swap names
+	for i, v := range p.rename {
+		err = tx.move(paths.Endpoint(v.from.Name), paths.Abs(fmt.Sprintf(".brev-rename-%d.py", i)))
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range p.remove {
+		err = tx.remove(paths.Endpoint(v.Name))
+		if err != nil {
+			return err
+		}
+		delete(newManifest, paths.Layout.EndpointFile(v.Name))
+	}
+	for i, v := range p.rename {
+		err = tx.move(paths.Abs(fmt.Sprintf(".brev-rename-%d.py", i)), paths.Endpoint(v.to.Name))
+		if err != nil {
+			return err
+		}
+		delete(newManifest, paths.Layout.EndpointFile(v.from.Name))
+		if hash, ok := manifest[paths.Layout.EndpointFile(v.from.Name)]; ok {
+			newManifest[paths.Layout.EndpointFile(v.to.Name)] = hash
+		}
+	}
+	for _, v := range p.add {
+		err = tx.write(paths.Endpoint(v.Name), v.Code)
+		if err != nil {
+			return err
+		}
+		newManifest.Record(paths.Layout.EndpointFile(v.Name), v.Code)
+	}
+
+	tx.onRollback(func() error {
+		return brevCtx.Local.SetEndpoints(localEndpoints)
+	})
+	err = brevCtx.Local.SetEndpoints(p.endpoints)
+	if err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		return brevCtx.Local.SetManifest(manifest)
+	})
+	return brevCtx.Local.SetManifest(newManifest)
+}
+
+// fileTransaction performs file operations which can be undone as a whole
+type fileTransaction struct {
+	undo []func() error
+}
+
+func (tx *fileTransaction) onRollback(undo func() error) {
+	tx.undo = append(tx.undo, undo)
+}
+
+// rollback undoes every operation, most recent first
+func (tx *fileTransaction) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+}
+
+func (tx *fileTransaction) move(from string, to string) error {
+	err := files.Rename(from, to)
+	if os.IsNotExist(err) {
+		// nothing to move if the file was never pulled
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		return files.Rename(to, from)
+	})
+	return nil
+}
+
+func (tx *fileTransaction) remove(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = files.Remove(path)
+	if err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		return files.OverwriteString(path, string(contents))
+	})
+	return nil
+}
+
+func (tx *fileTransaction) write(path string, contents string) error {
+	previous, readErr := ioutil.ReadFile(path)
+	err := files.OverwriteString(path, contents)
+	if err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		if readErr != nil {
+			return files.Remove(path)
+		}
+		return files.OverwriteString(path, string(previous))
+	})
+	return nil
+}
+
+func reconcile(t *terminal.Terminal, newClient brev_api.ClientFactory, force bool) error {
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return err
+	}
+
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return err
+	}
+
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		return err
+	}
+
+	localEndpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	remoteEndpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	manifest, err := brevCtx.Local.GetManifest()
+	if err != nil {
+		return err
+	}
+
+	plan := planReconcile(localEndpoints, remoteEndpoints)
+	if plan.empty() {
+		// refresh details such as methods and URIs
+		err = brevCtx.Local.SetEndpoints(remoteEndpoints)
+		if err != nil {
+			return err
+		}
+		t.Vprint(t.Green("Endpoints of %s are in sync 🥞", project.Name))
+		return nil
+	}
+
+	t.Vprint(t.Yellow("Endpoints of %s changed remotely:", project.Name))
+	plan.print(t, paths.Layout)
+
+	err = plan.check(paths, manifest, force)
+	if err != nil {
+		return err
+	}
+
+	err = plan.apply(brevCtx, paths, localEndpoints, manifest)
+	if err != nil {
+		t.Errprint(err, "Failed to reconcile endpoints, no changes were made")
+		return err
+	}
+
+	t.Vprint(t.Green("\nYour endpoints are synced 🥞"))
+	return nil
+}
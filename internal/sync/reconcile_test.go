@@ -0,0 +1,131 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_api/brevtest"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func TestPlanReconcile(t *testing.T) {
+	local := []brev_api.Endpoint{{Id: "1", Name: "a"}, {Id: "2", Name: "b"}, {Id: "3", Name: "c"}}
+	remote := []brev_api.Endpoint{{Id: "1", Name: "a"}, {Id: "2", Name: "renamed"}, {Id: "4", Name: "d"}}
+
+	plan := planReconcile(local, remote)
+	if len(plan.add) != 1 || plan.add[0].Name != "d" {
+		t.Errorf("add = %+v, want d", plan.add)
+	}
+	if len(plan.remove) != 1 || plan.remove[0].Name != "c" {
+		t.Errorf("remove = %+v, want c", plan.remove)
+	}
+	if len(plan.rename) != 1 || plan.rename[0].from.Name != "b" || plan.rename[0].to.Name != "renamed" {
+		t.Errorf("rename = %+v, want b -> renamed", plan.rename)
+	}
+	if planReconcile(remote, remote).empty() != true {
+		t.Error("plan for identical endpoints is not empty")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	project, path := brevtest.SetupProject(t, fake, "hello", "world", "moon")
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+	files.OverwriteString(filepath.Join(path, "world.py"), "WORLD = 1\n")
+	files.OverwriteString(filepath.Join(path, "moon.py"), "MOON = 1\n")
+	if err := push(terminal.New(), fake.Factory(), false, false, resolveNone, 4); err != nil {
+		t.Fatalf("push() returned error: %v", err)
+	}
+
+	// a teammate adds, deletes and swaps endpoint names in the console
+	fake.CreateEndpoint("added", project.Id, nil, "")
+	fake.RemoveEndpoint(fake.Endpoints[0].Id)
+	world, moon := fake.Endpoints[0], fake.Endpoints[1]
+	fake.UpdateEndpoint(world.Id, brev_api.RequestUpdateEndpoint{Name: "moon", Code: world.Code})
+	fake.UpdateEndpoint(moon.Id, brev_api.RequestUpdateEndpoint{Name: "world", Code: moon.Code})
+
+	if err := reconcile(terminal.New(), fake.Factory(), false); err != nil {
+		t.Fatalf("reconcile() returned error: %v", err)
+	}
+
+	for file, want := range map[string]string{
+		"moon.py":  "WORLD = 1\n",
+		"world.py": "MOON = 1\n",
+		"added.py": fake.Endpoints[2].Code,
+	} {
+		got, err := files.ReadString(filepath.Join(path, file))
+		if err != nil || got != want {
+			t.Errorf("%s = %q, %v, want %q", file, got, err, want)
+		}
+	}
+	if exists, _ := files.Exists(filepath.Join(path, "hello.py")); exists {
+		t.Error("hello.py was not deleted")
+	}
+
+	brevCtx, _ := brev_ctx.New(fake.Factory())
+	endpoints, _ := brevCtx.Local.GetEndpoints(nil)
+	if len(endpoints) != 3 {
+		t.Errorf("local endpoints = %+v, want the 3 remote ones", endpoints)
+	}
+}
+
+func TestReconcileKeepsUnpushedChanges(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello")
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+	files.OverwriteString(filepath.Join(path, "hello.py"), "unpushed\n")
+	fake.RemoveEndpoint(fake.Endpoints[0].Id)
+
+	if err := reconcile(terminal.New(), fake.Factory(), false); err == nil {
+		t.Fatal("reconcile() deleted a file with unpushed changes")
+	}
+	if got, _ := files.ReadString(filepath.Join(path, "hello.py")); got != "unpushed\n" {
+		t.Errorf("hello.py = %q", got)
+	}
+
+	if err := reconcile(terminal.New(), fake.Factory(), true); err != nil {
+		t.Fatalf("reconcile() with force returned error: %v", err)
+	}
+	if exists, _ := files.Exists(filepath.Join(path, "hello.py")); exists {
+		t.Error("hello.py was not deleted with --force")
+	}
+}
+
+func TestFileTransactionRollback(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.py")
+	b := filepath.Join(dir, "b.py")
+	files.OverwriteString(a, "a\n")
+	files.OverwriteString(b, "b\n")
+
+	tx := &fileTransaction{}
+	if err := tx.remove(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.write(b, "changed\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.write(filepath.Join(dir, "c.py"), "c\n"); err != nil {
+		t.Fatal(err)
+	}
+	tx.rollback()
+
+	if got, _ := files.ReadString(a); got != "a\n" {
+		t.Errorf("a.py = %q after rollback", got)
+	}
+	if got, _ := files.ReadString(b); got != "b\n" {
+		t.Errorf("b.py = %q after rollback", got)
+	}
+	if exists, _ := files.Exists(filepath.Join(dir, "c.py")); exists {
+		t.Error("c.py still exists after rollback")
+	}
+}
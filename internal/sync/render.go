@@ -0,0 +1,218 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+	"github.com/brevdev/brev-go-cli/internal/textdiff"
+)
+
+// numberedLine is a line of one side of a diff along with its line number
+type numberedLine struct {
+	number   int
+	text     string
+	segments []textdiff.Segment
+}
+
+// diffRow is a line of a side-by-side diff. Unchanged lines fill both sides; a
+// deleted line is paired with the inserted line replacing it, if there is one.
+type diffRow struct {
+	changed bool
+	old     *numberedLine
+	new     *numberedLine
+}
+
+// hunkRows numbers the lines of the hunk and pairs up the deleted and inserted lines
+// of each change. Paired lines are compared word by word.
+func hunkRows(hunk textdiff.Hunk) []diffRow {
+	oldNumber := hunk.OldStart
+	if hunk.OldLines == 0 {
+		oldNumber += 1
+	}
+	newNumber := hunk.NewStart
+	if hunk.NewLines == 0 {
+		newNumber += 1
+	}
+
+	var rows []diffRow
+	var deleted, inserted []numberedLine
+	flush := func() {
+		for i := 0; i < len(deleted) || i < len(inserted); i++ {
+			row := diffRow{changed: true}
+			if i < len(deleted) {
+				row.old = &deleted[i]
+			}
+			if i < len(inserted) {
+				row.new = &inserted[i]
+			}
+			if row.old != nil && row.new != nil {
+				oldSegments, newSegments := textdiff.Words(row.old.text, row.new.text)
+				// highlighting words only helps when most of the line stayed the same
+				if unchangedShare(oldSegments) >= 0.5 && unchangedShare(newSegments) >= 0.5 {
+					row.old.segments, row.new.segments = oldSegments, newSegments
+				}
+			}
+			rows = append(rows, row)
+		}
+		deleted, inserted = nil, nil
+	}
+
+	for _, line := range hunk.Lines {
+		text := strings.TrimSuffix(line.Text, "\n")
+		switch line.Op {
+		case textdiff.Delete:
+			deleted = append(deleted, numberedLine{number: oldNumber, text: text})
+			oldNumber += 1
+		case textdiff.Insert:
+			inserted = append(inserted, numberedLine{number: newNumber, text: text})
+			newNumber += 1
+		default:
+			flush()
+			rows = append(rows, diffRow{
+				old: &numberedLine{number: oldNumber, text: text},
+				new: &numberedLine{number: newNumber, text: text},
+			})
+			oldNumber += 1
+			newNumber += 1
+		}
+	}
+	flush()
+	return rows
+}
+
+func unchangedShare(segments []textdiff.Segment) float64 {
+	total, unchanged := 0, 0
+	for _, segment := range segments {
+		total += len(segment.Text)
+		if !segment.Changed {
+			unchanged += len(segment.Text)
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(unchanged) / float64(total)
+}
+
+// renderDiff renders a file diff for the terminal with line numbers and word-level
+// highlighting. A positive width renders the old and new code side by side.
+func renderDiff(t *terminal.Terminal, d fileDiff, width int) string {
+	var b strings.Builder
+	b.WriteString(t.Yellow("%s (%s)", d.File, d.Status) + "\n")
+	for _, hunk := range d.hunks {
+		b.WriteString(t.Yellow("%s", hunk.Header()) + "\n")
+		rows := hunkRows(hunk)
+		if width > 0 {
+			renderSideBySide(t, &b, rows, width)
+		} else {
+			renderUnified(t, &b, rows)
+		}
+	}
+	return b.String()
+}
+
+func renderUnified(t *terminal.Terminal, b *strings.Builder, rows []diffRow) {
+	for i := 0; i < len(rows); {
+		if !rows[i].changed {
+			fmt.Fprintf(b, "%5d %5d │  %s\n", rows[i].old.number, rows[i].new.number, expandTabs(rows[i].old.text))
+			i += 1
+			continue
+		}
+
+		// print a change's deleted lines before its inserted lines
+		end := i
+		for end < len(rows) && rows[end].changed {
+			end += 1
+		}
+		for _, row := range rows[i:end] {
+			if row.old != nil {
+				fmt.Fprintf(b, "%5d %5s │ %s\n", row.old.number, "", colorLine("-", *row.old, t.Red, -1))
+			}
+		}
+		for _, row := range rows[i:end] {
+			if row.new != nil {
+				fmt.Fprintf(b, "%5s %5d │ %s\n", "", row.new.number, colorLine("+", *row.new, t.Green, -1))
+			}
+		}
+		i = end
+	}
+}
+
+func renderSideBySide(t *terminal.Terminal, b *strings.Builder, rows []diffRow, width int) {
+	// each side holds a line number, a marker and the code
+	column := (width - 3) / 2
+	textWidth := column - 8
+	if textWidth < 10 {
+		textWidth = 10
+	}
+
+	side := func(line *numberedLine, marker string, lineColor func(format string, a ...interface{}) string) string {
+		if line == nil {
+			return strings.Repeat(" ", textWidth+8)
+		}
+		var text string
+		if marker == " " {
+			text = "  " + truncate(expandTabs(line.text), textWidth)
+		} else {
+			text = colorLine(marker, *line, lineColor, textWidth)
+		}
+		visible := 2 + utf8.RuneCountInString(truncate(expandTabs(line.text), textWidth))
+		return fmt.Sprintf("%5d %s%s", line.number, text, strings.Repeat(" ", textWidth+2-visible))
+	}
+
+	for _, row := range rows {
+		if !row.changed {
+			fmt.Fprintf(b, "%s │ %s\n", side(row.old, " ", nil), side(row.new, " ", nil))
+			continue
+		}
+		fmt.Fprintf(b, "%s │ %s\n",
+			side(row.old, "-", t.Red),
+			side(row.new, "+", t.Green))
+	}
+}
+
+// colorLine renders a changed line behind its colored marker. When the line was paired
+// with another only the changed words are colored, otherwise the whole line is. A
+// non-negative width truncates the text.
+func colorLine(marker string, line numberedLine, lineColor func(format string, a ...interface{}) string, width int) string {
+	segments := line.segments
+	if segments == nil {
+		segments = []textdiff.Segment{{Text: line.text, Changed: true}}
+	}
+
+	remaining := width
+	rendered := lineColor("%s ", marker)
+	for _, segment := range segments {
+		text := expandTabs(segment.Text)
+		if width >= 0 {
+			if remaining <= 0 {
+				break
+			}
+			if utf8.RuneCountInString(text) > remaining {
+				text = truncate(text, remaining)
+			}
+			remaining -= utf8.RuneCountInString(text)
+		}
+		if segment.Changed {
+			rendered += lineColor("%s", text)
+		} else {
+			rendered += text
+		}
+	}
+	return rendered
+}
+
+// truncate shortens the text to at most width characters, marking cut text with an ellipsis
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
+
+func expandTabs(text string) string {
+	return strings.ReplaceAll(text, "\t", "    ")
+}
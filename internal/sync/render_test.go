@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+	"github.com/brevdev/brev-go-cli/internal/textdiff"
+)
+
+func testFileDiff(remote string, local string) fileDiff {
+	return fileDiff{
+		Name:   "hello",
+		File:   "hello.py",
+		Status: diffModified,
+		hunks:  textdiff.Hunks(textdiff.Diff(remote, local), 1),
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	d := testFileDiff("a\n\nb\nc\n%d\n", "a\n\nB\nc\n%d\n")
+	got := renderDiff(terminal.New(), d, 0)
+	want := "hello.py (modified)\n" +
+		"@@ -2,3 +2,3 @@\n" +
+		"    2     2 │  \n" +
+		"    3       │ - b\n" +
+		"          3 │ + B\n" +
+		"    4     4 │  c\n"
+	if got != want {
+		t.Errorf("renderDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderDiffColors(t *testing.T) {
+	color.NoColor = false
+	term := terminal.New()
+
+	// a paired line only colors its changed words, other lines are colored whole
+	d := testFileDiff("x = f(a)\n", "x = f(b)\ny\n")
+	got := renderDiff(term, d, 0)
+	if want := term.Red("%s ", "-") + "x = f(" + term.Red("%s", "a") + ")"; !strings.Contains(got, want) {
+		t.Errorf("renderDiff() =\n%q\nwant the deleted word colored: %q", got, want)
+	}
+	if want := term.Green("%s ", "+") + term.Green("%s", "y"); !strings.Contains(got, want) {
+		t.Errorf("renderDiff() =\n%q\nwant the inserted line colored: %q", got, want)
+	}
+}
+
+func TestRenderSideBySide(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	d := testFileDiff("def get():\n    return 1\n", "def get():\n    return 2\n    # a very long comment that will not fit into its column at all\n")
+	lines := strings.Split(strings.TrimSuffix(renderDiff(terminal.New(), d, 60), "\n"), "\n")
+	for _, line := range lines[2:] {
+		if width := utf8.RuneCountInString(line); width > 60 {
+			t.Errorf("line is %d characters wide: %q", width, line)
+		}
+	}
+	if !strings.Contains(lines[3], "-     return 1") || !strings.Contains(lines[3], "+     return 2") {
+		t.Errorf("changed lines are not side by side: %q", lines[3])
+	}
+}
+
+func TestHunkRowsHighlightWords(t *testing.T) {
+	hunks := textdiff.Hunks(textdiff.Diff("x = compute(a, b)\n", "x = compute(a, c)\n"), 0)
+	rows := hunkRows(hunks[0])
+	if len(rows) != 1 || rows[0].old == nil || rows[0].new == nil {
+		t.Fatalf("expected one paired row, got %+v", rows)
+	}
+	var changed []string
+	for _, segment := range rows[0].new.segments {
+		if segment.Changed {
+			changed = append(changed, segment.Text)
+		}
+	}
+	if strings.Join(changed, "") != "c" {
+		t.Errorf("highlighted words = %q, want c", changed)
+	}
+}
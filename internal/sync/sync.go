@@ -1,61 +1,132 @@
 package sync
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/brevdev/brev-go-cli/internal/brev_api"
 	"github.com/brevdev/brev-go-cli/internal/cmdcontext"
+	"github.com/brevdev/brev-go-cli/internal/parallel"
 	"github.com/brevdev/brev-go-cli/internal/terminal"
 )
 
-func NewCmdPush(t *terminal.Terminal) *cobra.Command {
+func NewCmdPush(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var watchChanges bool
+	var force bool
+	var inferMethods bool
+	var debounce time.Duration
+	var conflicts conflictFlags
+	var concurrency int
+
 	cmd := &cobra.Command{
 		Use:         "push",
 		Annotations: map[string]string{"code": ""},
 		Short:       "Push your local changes to remote",
-		Example:     `  brev push`,
+		Example: `  brev push
+  brev push --watch
+  brev push --force
+  brev push --infer-methods
+  brev push --ours`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			_, err = brev_api.CheckOutsideBrevErrorMessage(t)
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
 			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return push(t)
+			res, err := conflicts.resolution()
+			if err != nil {
+				return err
+			}
+			if watchChanges {
+				return watch(t, newClient, debounce, force, inferMethods, res, concurrency)
+			}
+			return push(t, newClient, force, inferMethods, res, concurrency)
 		},
 	}
 
+	cmd.Flags().BoolVarP(&watchChanges, "watch", "w", false, "push, then keep running and push files as they are saved")
+	cmd.Flags().BoolVar(&force, "force", false, "upload every file, even those unchanged since the last push or pull")
+	cmd.Flags().BoolVar(&inferMethods, "infer-methods", false, "set the methods of each endpoint to the get(), post(), put() and delete() functions it defines")
+	cmd.Flags().DurationVar(&debounce, "debounce", 300*time.Millisecond, "wait this long after the last change before pushing")
+	conflicts.register(cmd)
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "j", parallel.DefaultConcurrency, "number of files to transfer at once")
+
 	return cmd
 }
 
-func NewCmdPull(t *terminal.Terminal) *cobra.Command {
+func NewCmdPull(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var conflicts conflictFlags
+	var concurrency int
 
 	cmd := &cobra.Command{
 		Use:         "pull",
 		Annotations: map[string]string{"code": ""},
 		Short:       "Pull latest changes from your server",
-		Example:     `  brev pull`,
+		Example: `  brev pull
+  brev pull --theirs
+  brev pull --markers`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			_, err = brev_api.CheckOutsideBrevErrorMessage(t)
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
 			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return pull(t)
+			res, err := conflicts.resolution()
+			if err != nil {
+				return err
+			}
+			return pull(t, newClient, res, concurrency)
 		},
 	}
 
+	conflicts.register(cmd)
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "j", parallel.DefaultConcurrency, "number of files to transfer at once")
+
 	return cmd
 }
 
-func NewCmdDiff(t *terminal.Terminal) *cobra.Command {
+func NewCmdSync(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:         "sync",
+		Annotations: map[string]string{"code": ""},
+		Short:       "Pick up endpoints created, renamed or deleted in the console",
+		Long: `Compare your local endpoints with the ones deployed in the console, then add,
+rename and delete local endpoint files to match. The changes are applied all at once or not at all.`,
+		Example: `  brev sync
+  brev sync --force`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reconcile(t, newClient, force)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite untracked files and delete files with unpushed changes")
+
+	return cmd
+}
+
+func NewCmdDiff(t *terminal.Terminal, newClient brev_api.ClientFactory) *cobra.Command {
+	var options diffOptions
 
 	cmd := &cobra.Command{
 		Use:         "diff",
@@ -65,19 +136,41 @@ func NewCmdDiff(t *terminal.Terminal) *cobra.Command {
 			from an active brev project directory, run:
 
 			brev diff
-		`, PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+
+		To check in CI that the deployed code matches the repository, run:
+
+			brev diff --exit-code --name-only
+		`,
+		Example: `  brev diff
+  brev diff --side-by-side --context 5
+  brev diff --format unified > drift.patch
+  brev diff --format json
+  brev diff --exit-code --name-only`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			err := cmdcontext.InvokeParentPersistentPreRun(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			_, err = brev_api.CheckOutsideBrevErrorMessage(t)
+			_, err = brev_api.CheckOutsideBrevErrorMessage(t, newClient)
 			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return diffCmd(t)
+			if options.format != diffFormatText && options.format != diffFormatUnified && options.format != diffFormatJSON {
+				return fmt.Errorf("unknown format %q, expected text, unified or json", options.format)
+			}
+			return diffCmd(t, newClient, options)
 		},
 	}
 
+	cmd.Flags().StringVar(&options.format, "format", diffFormatText, "output format: text, unified or json")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{diffFormatText, diffFormatUnified, diffFormatJSON}, cobra.ShellCompDirectiveNoSpace
+	})
+	cmd.Flags().IntVarP(&options.context, "context", "U", 3, "number of unchanged lines to show around each change")
+	cmd.Flags().BoolVar(&options.nameOnly, "name-only", false, "only list the files which differ")
+	cmd.Flags().BoolVar(&options.exitCode, "exit-code", false, "exit with status 1 if any file differs, even only in whitespace")
+	cmd.Flags().BoolVarP(&options.sideBySide, "side-by-side", "y", false, "show the deployed and local code next to each other")
+
 	return cmd
 }
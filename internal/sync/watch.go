@@ -0,0 +1,345 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/layout"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+// watcher deploys the endpoint and module files of a project whenever their
+// contents differ from what it last pushed.
+type watcher struct {
+	t       *terminal.Terminal
+	brevCtx *brev_ctx.BrevContext
+	project *brev_api.Project
+	paths   *layout.Paths
+	module  *brev_api.Module
+
+	// inferMethods sets the methods of each deployed endpoint to the handlers it defines
+	inferMethods bool
+
+	// pushed holds the last known remote contents, keyed by file name relative to
+	// the project root
+	pushed map[string]string
+}
+
+func newWatcher(t *terminal.Terminal, brevCtx *brev_ctx.BrevContext, inferMethods bool) (*watcher, error) {
+	paths, err := brevCtx.Global.GetProjectFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := brevCtx.Local.GetProject()
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: project.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	localEndpoints, err := brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	remoteEndpoints, err := brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: project.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := brevCtx.Local.GetManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	syncFiles, err := collectSyncFiles(paths, manifest, module, localEndpoints, remoteEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	err = checkConflicts(syncFiles, resolveNone)
+	if err != nil {
+		return nil, err
+	}
+
+	// local changes are deployed right away, remote changes are left alone until the
+	// file is saved again
+	pushed := make(map[string]string)
+	for _, f := range syncFiles {
+		if f.state == stateLocalChanged && f.remoteExists {
+			pushed[f.name] = f.remote
+		} else if f.localExists {
+			pushed[f.name] = f.local
+		}
+	}
+
+	return &watcher{
+		t:            t,
+		brevCtx:      brevCtx,
+		project:      project,
+		paths:        paths,
+		module:       module,
+		inferMethods: inferMethods,
+		pushed:       pushed,
+	}, nil
+}
+
+// deploy pushes the given files of the project whose contents changed since they
+// were last pushed. Files which are neither the module nor a local endpoint are ignored,
+// and files changed remotely since they were last synced are skipped.
+func (w *watcher) deploy(fileNames []string) error {
+	endpoints, err := w.brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: w.project.Id,
+	})
+	if err != nil {
+		return err
+	}
+	endpointsByFile := make(map[string]brev_api.Endpoint)
+	for _, v := range endpoints {
+		endpointsByFile[w.paths.Layout.EndpointFile(v.Name)] = v
+	}
+
+	manifest, err := w.brevCtx.Local.GetManifest()
+	if err != nil {
+		return err
+	}
+
+	// the remote code is checked against the manifest before each push, as it may
+	// have been edited while watching
+	module, err := w.brevCtx.Remote.GetModule(&brev_ctx.GetModulesOptions{ProjectID: w.project.Id})
+	if err != nil {
+		return err
+	}
+	remoteEndpoints, err := w.brevCtx.Remote.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: w.project.Id,
+	})
+	if err != nil {
+		return err
+	}
+	remoteCode := make(map[string]string)
+	for _, v := range remoteEndpoints {
+		remoteCode[v.Id] = v.Code
+	}
+
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		endpoint, isEndpoint := endpointsByFile[fileName]
+		isModule := fileName == w.paths.Layout.ModuleFile(w.module.Name)
+		if !isEndpoint && !isModule {
+			continue
+		}
+
+		source, err := files.ReadString(w.paths.Abs(fileName))
+		if err != nil {
+			// editors may briefly remove a file while saving it
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if previous, ok := w.pushed[fileName]; ok && previous == source {
+			continue
+		}
+		if hasConflictMarkers(source) {
+			w.t.Vprint(w.t.Yellow("Skipping %s, it still contains conflict markers", fileName))
+			continue
+		}
+		remote, remoteExists := module.Source, true
+		if !isModule {
+			remote, remoteExists = remoteCode[endpoint.Id]
+		}
+		if !remoteExists {
+			w.t.Vprint(w.t.Yellow("Skipping %s, it was removed remotely. Run brev pull to take the removal", fileName))
+			continue
+		}
+		if base, ok := manifest[fileName]; ok && base != brev_ctx.HashContents(remote) && remote != source {
+			w.t.Vprint(w.t.Yellow("Skipping %s, it was changed remotely since it was last synced. Run brev pull to merge the changes", fileName))
+			continue
+		}
+
+		timestamp := time.Now().Format("15:04:05")
+		if isModule {
+			response, err := w.brevCtx.Remote.SetModule(&brev_ctx.SetModulesOptions{
+				ProjectID: w.project.Id,
+				ModuleID:  w.module.Id,
+				Source:    source,
+			})
+			if err != nil {
+				w.t.Errprint(err, fmt.Sprintf("Failed to deploy %s", fileName))
+				continue
+			}
+			w.t.Vprint(fmt.Sprintf("%s %s %s", timestamp, w.t.Green("deployed"), fileName))
+			if stdout := strings.TrimRight(response.StdOut, "\n"); stdout != "" {
+				w.t.Vprint(stdout)
+			}
+		} else {
+			methods := endpoint.Methods
+			if inferred := brev_api.InferMethods(source); w.inferMethods && len(inferred) > 0 {
+				methods = inferred
+			}
+			_, err := w.brevCtx.Remote.SetEndpoint(brev_api.Endpoint{
+				Id:      endpoint.Id,
+				Name:    endpoint.Name,
+				Methods: methods,
+				Code:    source,
+			})
+			if err != nil {
+				w.t.Errprint(err, fmt.Sprintf("Failed to deploy %s", fileName))
+				continue
+			}
+			w.t.Vprint(fmt.Sprintf("%s %s %s", timestamp, w.t.Green("deployed"), fileName))
+
+			if !brev_api.SameMethods(methods, endpoint.Methods) {
+				w.t.Vprint(w.t.Yellow("%s now serves %s", fileName, strings.Join(methods, ", ")))
+				for i, v := range endpoints {
+					if v.Id == endpoint.Id {
+						endpoints[i].Methods = methods
+					}
+				}
+				err = w.brevCtx.Local.SetEndpoints(endpoints)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		w.pushed[fileName] = source
+		manifest.Record(fileName, source)
+		err = w.brevCtx.Local.SetManifest(manifest)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run deploys every out of date file, then watches the project root and deploys
+// changed files once no further events arrived for the debounce duration.
+// It returns when interrupted.
+func (w *watcher) run(debounce time.Duration) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsWatcher.Close()
+
+	// fsnotify does not watch subdirectories, so each directory holding code is added
+	watched := make(map[string]bool)
+	for _, dir := range []string{w.paths.EndpointDir(), w.paths.ModuleDir()} {
+		if watched[dir] {
+			continue
+		}
+		watched[dir] = true
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+		err = fsWatcher.Add(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	var all []string
+	for fileName := range w.pushed {
+		all = append(all, fileName)
+	}
+	endpoints, err := w.brevCtx.Local.GetEndpoints(&brev_ctx.GetEndpointsOptions{
+		ProjectID: w.project.Id,
+	})
+	if err != nil {
+		return err
+	}
+	for _, v := range endpoints {
+		fileName := w.paths.Layout.EndpointFile(v.Name)
+		if _, ok := w.pushed[fileName]; !ok {
+			all = append(all, fileName)
+		}
+	}
+	err = w.deploy(all)
+	if err != nil {
+		return err
+	}
+
+	w.t.Vprint(w.t.Yellow("Watching %s for changes, press Ctrl+C to stop", w.paths.Root))
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	pending := make(map[string]bool)
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			fileName, ok := w.paths.Rel(event.Name)
+			if !ok || !strings.HasSuffix(fileName, ".py") {
+				continue
+			}
+			pending[fileName] = true
+			timer.Reset(debounce)
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.t.Errprint(err, "Failed to watch for changes")
+		case <-timer.C:
+			var fileNames []string
+			for fileName := range pending {
+				fileNames = append(fileNames, fileName)
+			}
+			pending = make(map[string]bool)
+			err := w.deploy(fileNames)
+			if err != nil {
+				w.t.Errprint(err, "Failed to deploy changes")
+			}
+		}
+	}
+}
+
+func watch(t *terminal.Terminal, newClient brev_api.ClientFactory, debounce time.Duration, force bool, inferMethods bool, res resolution, concurrency int) error {
+	w, err := startWatcher(t, newClient, force, inferMethods, res, concurrency)
+	if err != nil {
+		return err
+	}
+	return w.run(debounce)
+}
+
+// startWatcher pushes the project like push does, settling conflicts with the given
+// resolution, then returns a watcher for the files saved afterwards
+func startWatcher(t *terminal.Terminal, newClient brev_api.ClientFactory, force bool, inferMethods bool, res resolution, concurrency int) (*watcher, error) {
+	err := push(t, newClient, force, inferMethods, res, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	brevCtx, err := brev_ctx.New(newClient)
+	if err != nil {
+		return nil, err
+	}
+	return newWatcher(t, brevCtx, inferMethods)
+}
@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+	"github.com/brevdev/brev-go-cli/internal/brev_api/brevtest"
+	"github.com/brevdev/brev-go-cli/internal/brev_ctx"
+	"github.com/brevdev/brev-go-cli/internal/brev_errors"
+	"github.com/brevdev/brev-go-cli/internal/files"
+	"github.com/brevdev/brev-go-cli/internal/terminal"
+)
+
+func TestWatcherDeploysOnlyChangedFiles(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	brevCtx, err := brev_ctx.New(fake.Factory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := newWatcher(terminal.New(), brevCtx, false)
+	if err != nil {
+		t.Fatalf("newWatcher() returned error: %v", err)
+	}
+
+	// a remote edit to an unchanged local file must not be overwritten
+	fake.Endpoints[1].Code = "remote edit"
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 1\n")
+	files.OverwriteString(filepath.Join(path, "shared.py"), "X = 1\n")
+
+	err = w.deploy([]string{"hello.py", "world.py", "shared.py", "notes.py"})
+	if err != nil {
+		t.Fatalf("deploy() returned error: %v", err)
+	}
+
+	if fake.Endpoints[0].Code != "def get():\n    return 1\n" {
+		t.Errorf("remote code for hello = %q", fake.Endpoints[0].Code)
+	}
+	if fake.Endpoints[1].Code != "remote edit" {
+		t.Errorf("unchanged world.py was pushed, remote code = %q", fake.Endpoints[1].Code)
+	}
+	if fake.Modules[0].Source != "X = 1\n" {
+		t.Errorf("remote module source = %q", fake.Modules[0].Source)
+	}
+
+	// saving the same contents again is a no-op
+	fake.Endpoints[0].Code = "remote edit"
+	err = w.deploy([]string{"hello.py"})
+	if err != nil {
+		t.Fatalf("deploy() returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != "remote edit" {
+		t.Errorf("unchanged hello.py was pushed again")
+	}
+}
+
+func TestWatcherSkipsRemoteEdits(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello")
+
+	brevCtx, err := brev_ctx.New(fake.Factory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := newWatcher(terminal.New(), brevCtx, false)
+	if err != nil {
+		t.Fatalf("newWatcher() returned error: %v", err)
+	}
+
+	// the remote code is edited while watching, then the file is saved
+	fake.Endpoints[0].Code = "def get():\n    return 2\n"
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 1\n")
+	files.OverwriteString(filepath.Join(path, "shared.py"), "X = 1\n")
+	fake.Modules[0].Source = "X = 2\n"
+
+	if err := w.deploy([]string{"hello.py", "shared.py"}); err != nil {
+		t.Fatalf("deploy() returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != "def get():\n    return 2\n" {
+		t.Errorf("the remote edit of hello was overwritten with %q", fake.Endpoints[0].Code)
+	}
+	if fake.Modules[0].Source != "X = 2\n" {
+		t.Errorf("the remote edit of shared was overwritten with %q", fake.Modules[0].Source)
+	}
+
+	// once pulled, saving deploys again
+	if err := pull(terminal.New(), fake.Factory(), resolveTheirs, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+	files.OverwriteString(filepath.Join(path, "hello.py"), "def get():\n    return 3\n")
+	if err := w.deploy([]string{"hello.py"}); err != nil {
+		t.Fatalf("deploy() returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != "def get():\n    return 3\n" {
+		t.Errorf("remote code for hello = %q, want the save after pulling", fake.Endpoints[0].Code)
+	}
+}
+
+func TestStartWatcherSettlesConflict(t *testing.T) {
+	fake := brev_api.NewFakeClient()
+	_, path := brevtest.SetupProject(t, fake, "hello", "world")
+
+	if err := pull(terminal.New(), fake.Factory(), resolveNone, 4); err != nil {
+		t.Fatalf("pull() returned error: %v", err)
+	}
+	local := "def get():\n    return 1\n"
+	files.OverwriteString(filepath.Join(path, "hello.py"), local)
+	fake.Endpoints[0].Code = "def get():\n    return 2\n"
+
+	_, err := startWatcher(terminal.New(), fake.Factory(), false, false, resolveNone, 4)
+	var conflict *brev_errors.SyncConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("startWatcher() error = %v, want a conflict", err)
+	}
+
+	// push --watch --ours keeps the local version and starts watching
+	w, err := startWatcher(terminal.New(), fake.Factory(), false, true, resolveOurs, 4)
+	if err != nil {
+		t.Fatalf("startWatcher() with --ours returned error: %v", err)
+	}
+	if fake.Endpoints[0].Code != local {
+		t.Errorf("remote code for hello = %q, want the local version", fake.Endpoints[0].Code)
+	}
+
+	// later saves keep inferring methods
+	files.OverwriteString(filepath.Join(path, "world.py"), "def put():\n    return 1\n")
+	if err := w.deploy([]string{"world.py"}); err != nil {
+		t.Fatalf("deploy() returned error: %v", err)
+	}
+	if methods := fake.Endpoints[1].Methods; len(methods) != 1 || methods[0] != "PUT" {
+		t.Errorf("remote methods of world = %v, want [PUT]", methods)
+	}
+}
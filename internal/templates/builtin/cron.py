@@ -0,0 +1,30 @@
+"""Scheduled job for {{.Project}}.
+
+Have a scheduler call GET {{.Domain}}/{{.Endpoint}} on the interval you need.
+The job records when it last ran in global storage, so overlapping or early
+calls can be skipped.
+"""
+import datetime
+
+import variables
+import shared
+from global_storage import storage_context
+
+KEY = "{{.Endpoint}}_last_run"
+MIN_INTERVAL = datetime.timedelta(minutes=1)
+
+
+def run_job():
+    # do the scheduled work here
+    print("running {{.Endpoint}}")
+
+
+def get():
+    now = datetime.datetime.utcnow()
+    last_run = storage_context.get(KEY)
+    if last_run and now - datetime.datetime.fromisoformat(last_run) < MIN_INTERVAL:
+        return {"skipped": True, "last_run": last_run}
+
+    run_job()
+    storage_context[KEY] = now.isoformat()
+    return {"skipped": False, "last_run": storage_context[KEY]}
@@ -0,0 +1,61 @@
+"""JSON CRUD handler for {{.Endpoint}} items, kept in global storage.
+
+    GET    {{.Endpoint}}?id=<id>     fetch one item, or all items without an id
+    POST   {{.Endpoint}}             create an item from the JSON body
+    PUT    {{.Endpoint}}?id=<id>     replace an item with the JSON body
+    DELETE {{.Endpoint}}?id=<id>     remove an item
+"""
+import uuid
+
+import variables
+import shared
+from global_storage import storage_context
+
+COLLECTION = "{{.Endpoint}}"
+
+
+def _items():
+    return dict(storage_context.get(COLLECTION) or {})
+
+
+def _save(items):
+    storage_context[COLLECTION] = items
+
+
+def get(id=None):
+    items = _items()
+    if id is None:
+        return {"items": list(items.values())}
+    if id not in items:
+        return {"error": "no item with id %s" % id}, 404
+    return items[id]
+
+
+def post(body=None):
+    if not isinstance(body, dict):
+        return {"error": "expected a JSON object"}, 400
+    items = _items()
+    item = dict(body, id=str(uuid.uuid4()))
+    items[item["id"]] = item
+    _save(items)
+    return item, 201
+
+
+def put(id=None, body=None):
+    items = _items()
+    if id not in items:
+        return {"error": "no item with id %s" % id}, 404
+    if not isinstance(body, dict):
+        return {"error": "expected a JSON object"}, 400
+    items[id] = dict(body, id=id)
+    _save(items)
+    return items[id]
+
+
+def delete(id=None):
+    items = _items()
+    if id not in items:
+        return {"error": "no item with id %s" % id}, 404
+    del items[id]
+    _save(items)
+    return {"deleted": id}
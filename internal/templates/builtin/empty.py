@@ -0,0 +1,7 @@
+import variables
+import shared
+from global_storage import storage_context
+
+
+def get():
+    return {}
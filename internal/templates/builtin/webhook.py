@@ -0,0 +1,30 @@
+"""Webhook receiver for {{.Project}}.
+
+Point your webhook at {{.Domain}}/{{.Endpoint}}. Each delivery is printed to the
+logs and the latest ones are kept in global storage for inspection with GET.
+"""
+import datetime
+
+import variables
+import shared
+from global_storage import storage_context
+
+KEY = "{{.Endpoint}}_deliveries"
+KEEP = 20
+
+
+def post(body=None):
+    print("received webhook:", body)
+
+    deliveries = list(storage_context.get(KEY) or [])
+    deliveries.append({
+        "received": datetime.datetime.utcnow().isoformat() + "Z",
+        "body": body,
+    })
+    storage_context[KEY] = deliveries[-KEEP:]
+
+    return {"ok": True}
+
+
+def get():
+    return {"deliveries": storage_context.get(KEY) or []}
@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/brevdev/brev-go-cli/internal/files"
+)
+
+//go:embed builtin/*.py
+var builtinFiles embed.FS
+
+const templatesDirectory = "templates"
+
+// Vars are the values available to templates, e.g. {{.Endpoint}}
+type Vars struct {
+	Endpoint string
+	Project  string
+	Domain   string
+}
+
+// Template is a source file new endpoints can start from
+type Template struct {
+	Name string
+	// Origin is "built-in" or the directory the template was loaded from
+	Origin string
+
+	text string
+}
+
+// GlobalDir returns the directory holding the user's own templates
+func GlobalDir() string {
+	return filepath.Join(files.GetHomeDir(), files.GetBrevDirectory(), templatesDirectory)
+}
+
+// ProjectDir returns the directory holding the templates of the project at the given root
+func ProjectDir(projectRoot string) string {
+	return filepath.Join(projectRoot, files.GetBrevDirectory(), templatesDirectory)
+}
+
+// List returns the available templates by name. User templates replace built-in
+// ones of the same name, and project templates replace both.
+func List(projectRoot string) ([]Template, error) {
+	byName := make(map[string]Template)
+
+	entries, err := builtinFiles.ReadDir("builtin")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		text, err := builtinFiles.ReadFile("builtin/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(entry.Name(), ".py")
+		byName[name] = Template{Name: name, Origin: "built-in", text: string(text)}
+	}
+
+	dirs := []string{GlobalDir()}
+	if projectRoot != "" {
+		dirs = append(dirs, ProjectDir(projectRoot))
+	}
+	for _, dir := range dirs {
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates from %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".py") {
+				continue
+			}
+			text, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template %s: %w", entry.Name(), err)
+			}
+			name := strings.TrimSuffix(entry.Name(), ".py")
+			byName[name] = Template{Name: name, Origin: dir, text: string(text)}
+		}
+	}
+
+	var templates []Template
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Names returns the names of the available templates
+func Names(projectRoot string) []string {
+	templates, _ := List(projectRoot)
+	var names []string
+	for _, t := range templates {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Render fills in the template with the given name
+func Render(name string, projectRoot string, vars Vars) (string, error) {
+	templates, err := List(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range templates {
+		if t.Name == name {
+			return t.Render(vars)
+		}
+	}
+	return "", fmt.Errorf("unknown template %s: expected one of %s", name, strings.Join(Names(projectRoot), ", "))
+}
+
+// Render fills in the template with the given values
+func (t Template) Render(vars Vars) (string, error) {
+	parsed, err := template.New(t.Name).Option("missingkey=error").Parse(t.text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s from %s: %w", t.Name, t.Origin, err)
+	}
+	var b bytes.Buffer
+	err = parsed.Execute(&b, vars)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template %s from %s: %w", t.Name, t.Origin, err)
+	}
+	return b.String(), nil
+}
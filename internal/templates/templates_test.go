@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brevdev/brev-go-cli/internal/brev_api"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	vars := Vars{Endpoint: "items", Project: "shop", Domain: "https://shop.brev.test"}
+	wantMethods := map[string][]string{
+		"crud":    {"GET", "POST", "PUT", "DELETE"},
+		"webhook": {"GET", "POST"},
+		"cron":    {"GET"},
+		"empty":   {"GET"},
+	}
+	for name, want := range wantMethods {
+		code, err := Render(name, "", vars)
+		if err != nil {
+			t.Fatalf("Render(%s) returned error: %v", name, err)
+		}
+		if strings.Contains(code, "{{") {
+			t.Errorf("Render(%s) left placeholders in the code:\n%s", name, code)
+		}
+		if methods := brev_api.InferMethods(code); !reflect.DeepEqual(methods, want) {
+			t.Errorf("template %s serves %v, want %v", name, methods, want)
+		}
+	}
+}
+
+func TestUserTemplates(t *testing.T) {
+	root, err := ioutil.TempDir("", "brev-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", root)
+	defer os.Setenv("HOME", oldHome)
+
+	project := filepath.Join(root, "project")
+	os.MkdirAll(GlobalDir(), 0755)
+	os.MkdirAll(ProjectDir(project), 0755)
+	ioutil.WriteFile(filepath.Join(GlobalDir(), "empty.py"), []byte("# user {{.Endpoint}}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(GlobalDir(), "mine.py"), []byte("# user {{.Project}}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(ProjectDir(project), "mine.py"), []byte("# project {{.Project}}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(ProjectDir(project), "broken.py"), []byte("# {{.Missing}}\n"), 0644)
+
+	vars := Vars{Endpoint: "hook", Project: "shop"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"empty", "# user hook\n"},
+		{"mine", "# project shop\n"},
+	}
+	for _, tt := range tests {
+		code, err := Render(tt.name, project, vars)
+		if err != nil || code != tt.want {
+			t.Errorf("Render(%s) = %q, %v; want %q", tt.name, code, err, tt.want)
+		}
+	}
+
+	if code, _ := Render("mine", "", vars); code != "# user shop\n" {
+		t.Errorf("Render(mine) outside a project = %q, want the user template", code)
+	}
+	if _, err := Render("broken", project, vars); err == nil {
+		t.Error("Render(broken) filled in an unknown variable")
+	}
+	if _, err := Render("nope", project, vars); err == nil || !strings.Contains(err.Error(), "crud") {
+		t.Errorf("Render(nope) error = %v, want the available templates", err)
+	}
+}
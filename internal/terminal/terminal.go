@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -52,7 +53,7 @@ func (t *Terminal) Print(a string) {
 }
 
 func (t *Terminal) Printf(format string, a ...interface{}) {
-	fmt.Fprintf(t.out, format, a)
+	fmt.Fprintf(t.out, format, a...)
 }
 
 func (t *Terminal) Vprint(a string) {
@@ -60,7 +61,7 @@ func (t *Terminal) Vprint(a string) {
 }
 
 func (t *Terminal) Vprintf(format string, a ...interface{}) {
-	fmt.Fprintf(t.verbose, format, a)
+	fmt.Fprintf(t.verbose, format, a...)
 }
 
 func (t *Terminal) Eprint(a string) {
@@ -68,7 +69,7 @@ func (t *Terminal) Eprint(a string) {
 }
 
 func (t *Terminal) Eprintf(format string, a ...interface{}) {
-	fmt.Fprintf(t.err, format, a)
+	fmt.Fprintf(t.err, format, a...)
 }
 
 func (t *Terminal) Errprint(err error, a string) {
@@ -76,18 +77,22 @@ func (t *Terminal) Errprint(err error, a string) {
 	if a != "" {
 		t.Eprint(t.Red(a))
 	}
-	if brevErr, ok := err.(brev_errors.BrevError); ok {
-		t.Eprint(t.Red(brevErr.Directive()))
-	}
+	t.printDirective(err)
 }
 
 func (t *Terminal) Errprintf(err error, format string, a ...interface{}) {
 	t.Eprint(t.Red("Error: " + err.Error()))
-	if a != nil {
-		t.Eprint(t.Red(format, a))
+	if format != "" {
+		t.Eprint(t.Red(format, a...))
 	}
-	if brevErr, ok := err.(brev_errors.BrevError); ok {
-		t.Eprint(t.Red(brevErr.Directive()))
+	t.printDirective(err)
+}
+
+// printDirective prints how to overcome the first BrevError found in the error's chain
+func (t *Terminal) printDirective(err error) {
+	var brevErr brev_errors.BrevError
+	if errors.As(err, &brevErr) && brevErr.Directive() != "" {
+		t.Eprint(t.Yellow("Fix: %s", brevErr.Directive()))
 	}
 }
 
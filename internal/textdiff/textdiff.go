@@ -0,0 +1,315 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Op is the kind of change a diff line represents
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Prefix returns the character marking the op in unified diffs
+func (o Op) Prefix() string {
+	switch o {
+	case Delete:
+		return "-"
+	case Insert:
+		return "+"
+	default:
+		return " "
+	}
+}
+
+// Line is a single line of a diff. Text keeps the line's trailing newline, if it
+// has one, so that a missing newline at the end of a file shows up as a change.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Hunk is a run of changed lines along with their surrounding context. Start
+// positions are 1-based line numbers, as in unified diff headers.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []Line
+}
+
+// Diff returns the line-by-line difference between the old and new text
+func Diff(old string, new string) []Line {
+	return diff(splitLines(old), splitLines(new))
+}
+
+// Segment is a run of text within a changed line
+type Segment struct {
+	Text    string
+	Changed bool
+}
+
+// Words compares two versions of a line word by word. It returns the segments of
+// each version, marking the words which were removed from the old line or added
+// to the new one.
+func Words(old string, new string) ([]Segment, []Segment) {
+	var oldSegments, newSegments []Segment
+	for _, token := range diff(splitWords(old), splitWords(new)) {
+		if token.Op != Insert {
+			oldSegments = appendSegment(oldSegments, token.Text, token.Op == Delete)
+		}
+		if token.Op != Delete {
+			newSegments = appendSegment(newSegments, token.Text, token.Op == Insert)
+		}
+	}
+	return oldSegments, newSegments
+}
+
+func appendSegment(segments []Segment, text string, changed bool) []Segment {
+	if len(segments) > 0 && segments[len(segments)-1].Changed == changed {
+		segments[len(segments)-1].Text += text
+		return segments
+	}
+	return append(segments, Segment{Text: text, Changed: changed})
+}
+
+// splitWords splits a line into runs of word characters, runs of whitespace and
+// single punctuation characters
+func splitWords(line string) []string {
+	kind := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 0
+		case unicode.IsSpace(r):
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	var words []string
+	start := 0
+	previous := -1
+	for i, r := range line {
+		current := kind(r)
+		if i > start && (current != previous || current == 2) {
+			words = append(words, line[start:i])
+			start = i
+		}
+		previous = current
+	}
+	if start < len(line) {
+		words = append(words, line[start:])
+	}
+	return words
+}
+
+// diff finds a shortest edit script turning a into b using Myers' algorithm
+func diff(a []string, b []string) []Line {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k; trace keeps v as it
+	// was before each edit distance d so the script can be recovered
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+	distance := 0
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x += 1
+				y += 1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				distance = d
+				break search
+			}
+		}
+	}
+
+	var reversed []Line
+	x, y := n, m
+	for d := distance; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Op: Equal, Text: a[x-1]})
+			x -= 1
+			y -= 1
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Op: Insert, Text: b[y-1]})
+			y -= 1
+		} else {
+			reversed = append(reversed, Line{Op: Delete, Text: a[x-1]})
+			x -= 1
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, Line{Op: Equal, Text: a[x-1]})
+		x -= 1
+		y -= 1
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// Changed reports whether any of the lines is an insertion or deletion
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Hunks groups the changed lines into hunks with up to context unchanged lines
+// around each change. Changes separated by at most twice the context share a hunk.
+func Hunks(lines []Line, context int) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+
+	// oldCount[i] and newCount[i] are the numbers of old and new lines before lines[i]
+	n := len(lines)
+	oldCount := make([]int, n+1)
+	newCount := make([]int, n+1)
+	for i, line := range lines {
+		oldCount[i+1] = oldCount[i]
+		newCount[i+1] = newCount[i]
+		if line.Op != Insert {
+			oldCount[i+1] += 1
+		}
+		if line.Op != Delete {
+			newCount[i+1] += 1
+		}
+	}
+
+	var hunks []Hunk
+	i := 0
+	for {
+		for i < n && lines[i].Op == Equal {
+			i += 1
+		}
+		if i == n {
+			return hunks
+		}
+
+		start := max(0, i-context)
+		end := i
+		for {
+			for end < n && lines[end].Op != Equal {
+				end += 1
+			}
+			next := end
+			for next < n && lines[next].Op == Equal {
+				next += 1
+			}
+			if next == n || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		stop := min(n, end+context)
+
+		hunk := Hunk{
+			OldStart: oldCount[start] + 1,
+			OldLines: oldCount[stop] - oldCount[start],
+			NewStart: newCount[start] + 1,
+			NewLines: newCount[stop] - newCount[start],
+			Lines:    lines[start:stop],
+		}
+		// an empty range names the line before it, as in diff(1)
+		if hunk.OldLines == 0 {
+			hunk.OldStart -= 1
+		}
+		if hunk.NewLines == 0 {
+			hunk.NewStart -= 1
+		}
+		hunks = append(hunks, hunk)
+		i = stop
+	}
+}
+
+// Header returns the hunk's @@ line
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// Unified renders the hunks as a unified diff between the given paths, suitable
+// for `patch` or `git apply`. Use /dev/null as a path for added or removed files.
+func Unified(oldPath string, newPath string, hunks []Hunk) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldPath, newPath)
+	for _, hunk := range hunks {
+		b.WriteString(hunk.Header() + "\n")
+		for _, line := range hunk.Lines {
+			b.WriteString(line.Op.Prefix() + strings.TrimSuffix(line.Text, "\n") + "\n")
+			if !strings.HasSuffix(line.Text, "\n") {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk"
+
+	got := Unified("a/x.py", "b/x.py", Hunks(Diff(old, new), 1))
+	want := `--- a/x.py
++++ b/x.py
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -10,1 +10,2 @@
+ j
++k
+\ No newline at end of file
+`
+	if got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHunksMergeNearbyChanges(t *testing.T) {
+	hunks := Hunks(Diff("a\nb\nc\nd\ne\n", "A\nb\nc\nd\nE\n"), 3)
+	if len(hunks) != 1 {
+		t.Fatalf("got %d hunks, want 1", len(hunks))
+	}
+	if header := hunks[0].Header(); header != "@@ -1,5 +1,5 @@" {
+		t.Errorf("Header() = %s", header)
+	}
+}
+
+func TestHunksEmptySides(t *testing.T) {
+	added := Hunks(Diff("", "a\nb\n"), 3)
+	if len(added) != 1 || added[0].Header() != "@@ -0,0 +1,2 @@" {
+		t.Errorf("added file hunks = %+v", added)
+	}
+	removed := Hunks(Diff("a\nb\n", ""), 3)
+	if len(removed) != 1 || removed[0].Header() != "@@ -1,2 +0,0 @@" {
+		t.Errorf("removed file hunks = %+v", removed)
+	}
+	if Changed(Diff("same\n", "same\n")) {
+		t.Error("Changed() = true for identical text")
+	}
+}
+
+// apply rebuilds the new text from the old text and the hunks
+func apply(old string, hunks []Hunk) string {
+	oldLines := splitLines(old)
+	var out []string
+	next := 0
+	for _, hunk := range hunks {
+		start := hunk.OldStart - 1
+		if hunk.OldLines == 0 {
+			start = hunk.OldStart
+		}
+		out = append(out, oldLines[next:start]...)
+		for _, line := range hunk.Lines {
+			if line.Op != Delete {
+				out = append(out, line.Text)
+			}
+		}
+		next = start + hunk.OldLines
+	}
+	out = append(out, oldLines[next:]...)
+	return strings.Join(out, "")
+}
+
+func TestHunksRoundTrip(t *testing.T) {
+	cases := [][2]string{
+		{"", "x\n"},
+		{"x\n", ""},
+		{"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", "0\n1\n2\n3\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"},
+		{"def get():\n    return 1\n", "def get():\n    return 1"},
+		{"a\n\n\nb\n", "a\n\nb\n\n"},
+	}
+	for _, c := range cases {
+		for context := 0; context < 4; context++ {
+			if got := apply(c[0], Hunks(Diff(c[0], c[1]), context)); got != c[1] {
+				t.Errorf("apply(%q) with context %d = %q, want %q", c[0], context, got, c[1])
+			}
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	oldSegments, newSegments := Words("    return x + 1", "    return y + 1")
+
+	wantOld := []Segment{{"    return ", false}, {"x", true}, {" + 1", false}}
+	wantNew := []Segment{{"    return ", false}, {"y", true}, {" + 1", false}}
+	if !reflect.DeepEqual(oldSegments, wantOld) {
+		t.Errorf("old segments = %+v, want %+v", oldSegments, wantOld)
+	}
+	if !reflect.DeepEqual(newSegments, wantNew) {
+		t.Errorf("new segments = %+v, want %+v", newSegments, wantNew)
+	}
+}